@@ -3,6 +3,9 @@ package app
 import (
 	"context"
 	"dev11/config"
+	"dev11/core"
+	"dev11/holiday"
+	"dev11/repository"
 	"dev11/server"
 	"dev11/service"
 	"dev11/transport/api"
@@ -17,11 +20,22 @@ const (
 	configName = "config"
 )
 
+// defaultUsers - пользователи тенанта по умолчанию
+var defaultUsers = []core.User{
+	{
+		ID:       "3",
+		UserName: "vlad",
+	},
+}
+
 type CalendarApp struct {
 	conf    *config.Config
 	handler Handler
 	serv    Server
+	storage *repository.Storage
 	evSv    api.EventServ
+	wdSv    api.WorkdaysServ
+	tnSv    api.TenantServ
 }
 
 func NewApp() *CalendarApp {
@@ -37,9 +51,12 @@ func (ca *CalendarApp) Run(contxt context.Context) {
 	_ = cancel
 
 	ca.setConfig()
+	ca.setStorage()
+	ca.setTenantsService()
+	ca.setEventService()
+	ca.setWorkdaysService()
 	ca.setHandler()
 	ca.setHttpServer()
-	ca.setEventService()
 	//ca.handler.AddMiddleware()
 
 	go func() {
@@ -67,15 +84,44 @@ func (ca *CalendarApp) setConfig() {
 }
 
 func (ca *CalendarApp) setHandler() {
-	ca.handler = api.NewHTTPHandler(ca.evSv)
+	ca.handler = api.NewHTTPHandler(ca.evSv, ca.wdSv, ca.tnSv)
 }
 
 func (ca *CalendarApp) setHttpServer() {
 	ca.serv = server.NewHttpServer(ca.conf, ca.handler.Handler())
 }
 
+func (ca *CalendarApp) setStorage() {
+	ca.storage = repository.NewStorage()
+
+	defaultTenant := core.Tenant{
+		ID:    core.DefaultTenantID,
+		Name:  "Default",
+		Quota: ca.conf.TenantsConf.DefaultQuota,
+	}
+
+	if err := ca.storage.CreateTenant(defaultTenant, defaultUsers); err != nil {
+		log.Fatalf("error creating default tenant: %s", err.Error())
+	}
+}
+
+func (ca *CalendarApp) setTenantsService() {
+	tenantsConf := ca.conf.TenantsConf
+	ca.tnSv = service.NewTenantsService(ca.storage, tenantsConf.AdminToken, tenantsConf.AllowHeader, tenantsConf.DefaultQuota)
+}
+
 func (ca *CalendarApp) setEventService() {
-	ca.evSv = service.NewEventsService()
+	ca.evSv = service.NewEventsService(ca.storage)
+}
+
+func (ca *CalendarApp) setWorkdaysService() {
+	cal, err := holiday.Load(ca.conf.HolidaysConf.Countries, ca.conf.HolidaysConf.Files)
+
+	if err != nil {
+		log.Fatalf("error loading holidays: %s", err.Error())
+	}
+
+	ca.wdSv = service.NewWorkdaysService(cal, ca.storage)
 }
 
 type Handler interface {
@@ -0,0 +1,296 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"dev11/core"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateFormat = "2006-01-02"
+
+// APIError - ошибка, которую возвращает клиент, если сервер ответил статусом не 2xx
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("calendar: server responded with %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+
+	return fmt.Sprintf("calendar: server responded with %d: %s", e.StatusCode, e.Message)
+}
+
+// Client - клиент HTTP API календаря
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	tenantID   string
+	token      string
+}
+
+// NewClient - создаёт клиент для сервера baseURL, если httpClient равен nil используется http.DefaultClient
+func NewClient(baseURL string, httpClient *http.Client) (*Client, error) {
+	u, err := url.Parse(baseURL)
+
+	if err != nil {
+		return nil, fmt.Errorf("calendar: bad server url %q: %w", baseURL, err)
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("calendar: bad server url %q: scheme and host are required", baseURL)
+	}
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		baseURL:    u,
+		httpClient: httpClient,
+	}, nil
+}
+
+// WithTenant - копия клиента, которая указывает тенанта в заголовке X-Tenant-ID
+func (c *Client) WithTenant(tenantID string) *Client {
+	cp := *c
+	cp.tenantID = tenantID
+
+	return &cp
+}
+
+// WithToken - копия клиента, которая авторизуется токеном тенанта
+func (c *Client) WithToken(token string) *Client {
+	cp := *c
+	cp.token = token
+
+	return &cp
+}
+
+func (c *Client) Create(ctx context.Context, event core.Event) (core.Event, error) {
+	var created core.Event
+
+	if err := c.do(ctx, http.MethodPost, "/create_event/", nil, event, &created); err != nil {
+		return core.Event{}, err
+	}
+
+	return created, nil
+}
+
+// CreateWarnHolidays - создаёт событие и возвращает предупреждения, если его дата выпадает на праздник или выходной
+func (c *Client) CreateWarnHolidays(ctx context.Context, event core.Event) (core.Event, []string, error) {
+	var created core.Event
+
+	query := url.Values{}
+	query.Set("warn_holidays", "true")
+
+	warnings, err := c.send(ctx, http.MethodPost, "/create_event/", query, event, &created)
+
+	if err != nil {
+		return core.Event{}, nil, err
+	}
+
+	return created, warnings, nil
+}
+
+func (c *Client) Update(ctx context.Context, event core.Event) error {
+	return c.do(ctx, http.MethodPut, "/update_event/", nil, event, nil)
+}
+
+func (c *Client) Delete(ctx context.Context, evID, userID string) error {
+	body := struct {
+		ID     string `json:"id"`
+		UserID string `json:"user_id"`
+	}{
+		ID:     evID,
+		UserID: userID,
+	}
+
+	return c.do(ctx, http.MethodDelete, "/delete_event/", nil, body, nil)
+}
+
+func (c *Client) EventsForDay(ctx context.Context, day time.Time, userID string, filter core.EventFilter) ([]core.Event, error) {
+	query := filterQuery(filter)
+	query.Set("day", day.Format(dateFormat))
+	query.Set("user_id", userID)
+
+	return c.events(ctx, "/events_for_day/", query)
+}
+
+func (c *Client) EventsForWeek(ctx context.Context, since time.Time, userID string, filter core.EventFilter) ([]core.Event, error) {
+	query := filterQuery(filter)
+	query.Set("since", since.Format(dateFormat))
+	query.Set("user_id", userID)
+
+	return c.events(ctx, "/events_for_week/", query)
+}
+
+func (c *Client) EventsForMonth(ctx context.Context, since time.Time, userID string, filter core.EventFilter) ([]core.Event, error) {
+	query := filterQuery(filter)
+	query.Set("since", since.Format(dateFormat))
+	query.Set("user_id", userID)
+
+	return c.events(ctx, "/events_for_month/", query)
+}
+
+// Tags - теги пользователя с количеством событий
+func (c *Client) Tags(ctx context.Context, userID string) ([]core.TagUsage, error) {
+	query := url.Values{}
+	query.Set("user_id", userID)
+
+	usage := make([]core.TagUsage, 0)
+
+	if err := c.do(ctx, http.MethodGet, "/tags", query, nil, &usage); err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// WorkdaysBetween - количество рабочих дней в [from, to)
+func (c *Client) WorkdaysBetween(ctx context.Context, from, to time.Time) (int, error) {
+	query := url.Values{}
+	query.Set("from", from.Format(dateFormat))
+	query.Set("to", to.Format(dateFormat))
+
+	var count core.WorkdaysCount
+
+	if err := c.do(ctx, http.MethodGet, "/workdays", query, nil, &count); err != nil {
+		return 0, err
+	}
+
+	return count.Workdays, nil
+}
+
+// AddWorkdays - дата через n рабочих дней после from
+func (c *Client) AddWorkdays(ctx context.Context, from time.Time, n int) (time.Time, error) {
+	query := url.Values{}
+	query.Set("from", from.Format(dateFormat))
+	query.Set("add", strconv.Itoa(n))
+
+	var added core.WorkdaysAdd
+
+	if err := c.do(ctx, http.MethodGet, "/workdays", query, nil, &added); err != nil {
+		return time.Time{}, err
+	}
+
+	return added.Date, nil
+}
+
+func filterQuery(filter core.EventFilter) url.Values {
+	query := url.Values{}
+
+	for _, tag := range filter.Tags {
+		query.Add("tag", tag)
+	}
+	if filter.Category != "" {
+		query.Set("category", filter.Category)
+	}
+
+	return query
+}
+
+func (c *Client) events(ctx context.Context, path string, query url.Values) ([]core.Event, error) {
+	events := make([]core.Event, 0)
+
+	if err := c.do(ctx, http.MethodGet, path, query, nil, &events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// do - отправляет запрос и декодирует поле "result" ответа в out
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	_, err := c.send(ctx, method, path, query, in, out)
+
+	return err
+}
+
+// send - то же, что do, но дополнительно возвращает предупреждения сервера
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, in, out interface{}) ([]string, error) {
+	u := c.baseURL.JoinPath(path)
+	u.RawQuery = query.Encode()
+
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+
+		if err != nil {
+			return nil, fmt.Errorf("calendar: can not marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+
+	if err != nil {
+		return nil, fmt.Errorf("calendar: can not create request: %w", err)
+	}
+
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.tenantID != "" {
+		req.Header.Set("X-Tenant-ID", c.tenantID)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return nil, fmt.Errorf("calendar: %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Result   json.RawMessage `json:"result"`
+		Warnings []string        `json:"warnings"`
+		Error    interface{}     `json:"error"`
+	}
+
+	data, err := io.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, fmt.Errorf("calendar: can not read response: %w", err)
+	}
+
+	errDecode := json.Unmarshal(data, &envelope)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+
+		if errDecode == nil && envelope.Error != nil {
+			apiErr.Message = strings.TrimSpace(fmt.Sprint(envelope.Error))
+		} else {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+
+		return nil, apiErr
+	}
+
+	if errDecode != nil {
+		return nil, fmt.Errorf("calendar: bad response: %w", errDecode)
+	}
+
+	if out == nil || len(envelope.Result) == 0 {
+		return envelope.Warnings, nil
+	}
+
+	if err := json.Unmarshal(envelope.Result, out); err != nil {
+		return nil, fmt.Errorf("calendar: bad response result: %w", err)
+	}
+
+	return envelope.Warnings, nil
+}
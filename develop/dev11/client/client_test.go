@@ -0,0 +1,308 @@
+package client
+
+import (
+	"context"
+	"dev11/core"
+	"dev11/holiday"
+	"dev11/repository"
+	"dev11/service"
+	"dev11/transport/api"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientRequests(t *testing.T) {
+	day := time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		call       func(c *Client) (interface{}, error)
+		wantMethod string
+		wantPath   string
+		wantQuery  string
+		answer     string
+		want       interface{}
+	}{
+		{
+			name: "create returns event with id",
+			call: func(c *Client) (interface{}, error) {
+				return c.Create(context.Background(), core.Event{Text: "meeting", Date: day, UserID: "3"})
+			},
+			wantMethod: http.MethodPost,
+			wantPath:   "/create_event/",
+			answer:     `{"result":{"id":"a1","text":"meeting","date":"2024-01-02T00:00:00Z","user_id":"3"}}`,
+			want:       core.Event{ID: "a1", Text: "meeting", Date: day, UserID: "3"},
+		},
+		{
+			name: "events for week sends since, user id and filter",
+			call: func(c *Client) (interface{}, error) {
+				return c.EventsForWeek(context.Background(), day, "3", core.EventFilter{Tags: []string{"work", "team"}, Category: "job"})
+			},
+			wantMethod: http.MethodGet,
+			wantPath:   "/events_for_week/",
+			wantQuery:  "category=job&since=2024-01-02&tag=work&tag=team&user_id=3",
+			answer:     `{"result":[{"id":"a1","text":"meeting","date":"2024-01-02T00:00:00Z","user_id":"3"}]}`,
+			want:       []core.Event{{ID: "a1", Text: "meeting", Date: day, UserID: "3"}},
+		},
+		{
+			name: "tags sends user id",
+			call: func(c *Client) (interface{}, error) {
+				return c.Tags(context.Background(), "3")
+			},
+			wantMethod: http.MethodGet,
+			wantPath:   "/tags",
+			wantQuery:  "user_id=3",
+			answer:     `{"result":[{"tag":"work","count":2}]}`,
+			want:       []core.TagUsage{{Tag: "work", Count: 2}},
+		},
+		{
+			name: "delete sends id in body",
+			call: func(c *Client) (interface{}, error) {
+				return nil, c.Delete(context.Background(), "a1", "3")
+			},
+			wantMethod: http.MethodDelete,
+			wantPath:   "/delete_event/",
+			answer:     `{"result":"ok"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.Method != tt.wantMethod {
+					t.Errorf("method = %s, want %s", req.Method, tt.wantMethod)
+				}
+				if req.URL.Path != tt.wantPath {
+					t.Errorf("path = %s, want %s", req.URL.Path, tt.wantPath)
+				}
+				if req.URL.RawQuery != tt.wantQuery {
+					t.Errorf("query = %s, want %s", req.URL.RawQuery, tt.wantQuery)
+				}
+				_, _ = w.Write([]byte(tt.answer))
+			}))
+			defer srv.Close()
+
+			c, err := NewClient(srv.URL, srv.Client())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := tt.call(c)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if tt.want == nil {
+				return
+			}
+
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(tt.want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("got %s, want %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+func TestClientAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"validate: text event is empty\n"}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Create(context.Background(), core.Event{UserID: "3"})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.Message != "validate: text event is empty" {
+		t.Errorf("error = %+v", apiErr)
+	}
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	cal, err := holiday.Load([]string{"RU"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storage := repository.NewStorage()
+	if err := storage.CreateTenant(core.Tenant{ID: core.DefaultTenantID}, []core.User{{ID: "3"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := api.NewHTTPHandler(
+		service.NewEventsService(storage),
+		service.NewWorkdaysService(cal, storage),
+		service.NewTenantsService(storage, "admin-secret", true, core.Quota{}),
+	)
+
+	return httptest.NewServer(handler.Handler())
+}
+
+func TestClientAgainstServer(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	day := time.Date(2024, time.March, 4, 0, 0, 0, 0, time.UTC)
+
+	created, err := c.Create(ctx, core.Event{Text: "standup", Date: day, UserID: "3", Tags: []string{"Team"}, Category: "job"})
+	if err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if created.ID == "" {
+		t.Fatal("create: server did not assign id")
+	}
+
+	created.Text = "retro"
+	if err := c.Update(ctx, created); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+
+	events, err := c.EventsForDay(ctx, day, "3", core.EventFilter{Tags: []string{"team"}, Category: "job"})
+	if err != nil {
+		t.Fatalf("day: %s", err)
+	}
+	if len(events) != 1 || events[0].Text != "retro" {
+		t.Fatalf("day: got %+v", events)
+	}
+
+	events, err = c.EventsForDay(ctx, day, "3", core.EventFilter{Category: "home"})
+	if err != nil || len(events) != 0 {
+		t.Fatalf("day: expected no events for other category, got %+v, %v", events, err)
+	}
+
+	usage, err := c.Tags(ctx, "3")
+	if err != nil {
+		t.Fatalf("tags: %s", err)
+	}
+	if len(usage) != 1 || usage[0] != (core.TagUsage{Tag: "team", Count: 1}) {
+		t.Fatalf("tags: got %+v", usage)
+	}
+
+	if err := c.Delete(ctx, created.ID, "3"); err != nil {
+		t.Fatalf("delete: %s", err)
+	}
+
+	events, err = c.EventsForDay(ctx, day, "3", core.EventFilter{})
+	if err != nil || len(events) != 0 {
+		t.Fatalf("day: expected no events after delete, got %+v, %v", events, err)
+	}
+
+	holidayEv, warnings, err := c.CreateWarnHolidays(ctx, core.Event{Text: "party", Date: time.Date(2024, time.March, 8, 0, 0, 0, 0, time.UTC), UserID: "3"})
+	if err != nil {
+		t.Fatalf("create with warnings: %s", err)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("create with warnings: got %v", warnings)
+	}
+	_ = c.Delete(ctx, holidayEv.ID, "3")
+
+	n, err := c.WorkdaysBetween(ctx, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || n != 17 {
+		t.Errorf("workdays between = %d, %v, want 17", n, err)
+	}
+
+	date, err := c.AddWorkdays(ctx, time.Date(2024, time.February, 21, 0, 0, 0, 0, time.UTC), 2)
+	if err != nil || !date.Equal(time.Date(2024, time.February, 26, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("add workdays = %s, %v, want 2024-02-26", date, err)
+	}
+}
+
+func TestClientTenants(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	createTenant := func(body string) core.Tenant {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/admin/tenants", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer admin-secret")
+
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		var created struct {
+			Result core.Tenant `json:"result"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&created); err != nil || resp.StatusCode != http.StatusCreated {
+			t.Fatalf("create tenant: status %d, %v", resp.StatusCode, err)
+		}
+
+		return created.Result
+	}
+
+	sales := createTenant(`{"id": "sales", "users": [{"id": "3"}], "quota": {"requests_per_minute": 2}}`)
+	support := createTenant(`{"id": "support", "users": [{"id": "3"}]}`)
+
+	base, err := NewClient(srv.URL, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	day := time.Date(2024, time.May, 2, 0, 0, 0, 0, time.UTC)
+
+	salesClient := base.WithToken(sales.Token)
+	if _, err := salesClient.Create(ctx, core.Event{Text: "deal", Date: day, UserID: "3"}); err != nil {
+		t.Fatalf("create in sales: %s", err)
+	}
+
+	if events, err := base.WithToken(support.Token).EventsForMonth(ctx, day, "3", core.EventFilter{}); err != nil || len(events) != 0 {
+		t.Errorf("support sees events of sales: %+v, %v", events, err)
+	}
+	if events, err := base.EventsForMonth(ctx, day, "3", core.EventFilter{}); err != nil || len(events) != 0 {
+		t.Errorf("default tenant sees events of sales: %+v, %v", events, err)
+	}
+
+	// тенант с токеном нельзя выбрать одним заголовком, даже когда заголовок разрешён
+	var apiErr *APIError
+	_, err = base.WithTenant("support").EventsForMonth(ctx, day, "3", core.EventFilter{})
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("header of tenant with token: got %v, want 401", err)
+	}
+
+	_, err = base.WithToken(sales.Token).WithTenant("support").EventsForMonth(ctx, day, "3", core.EventFilter{})
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("token of other tenant: got %v, want 403", err)
+	}
+
+	if _, err := salesClient.EventsForMonth(ctx, day, "3", core.EventFilter{}); err != nil {
+		t.Fatalf("sales events: %s", err)
+	}
+	_, err = salesClient.EventsForMonth(ctx, day, "3", core.EventFilter{})
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("rate limit: got %v, want 429", err)
+	}
+
+	resp, err := srv.Client().Get(srv.URL + "/admin/tenants")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("admin without token: status %d, want 403", resp.StatusCode)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	configEnv      = "CALENDARCTL_CONFIG"
+	defaultServer  = "http://localhost:8000"
+	defaultOutput  = "table"
+	defaultTimeout = 10 * time.Second
+)
+
+type ctlConfig struct {
+	Server  string
+	UserID  string
+	Tenant  string
+	Token   string
+	Output  string
+	Timeout time.Duration
+}
+
+// defaultConfigPath - путь до конфига из $CALENDARCTL_CONFIG или ~/.config/calendarctl/config.yml
+func defaultConfigPath() string {
+	if path := os.Getenv(configEnv); path != "" {
+		return path
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "calendarctl", "config.yml")
+}
+
+// loadConfig - читает конфиг, отсутствие файла по пути по умолчанию не считается ошибкой
+func loadConfig(path string, explicit bool) (*ctlConfig, error) {
+	v := viper.New()
+	v.SetDefault("server.url", defaultServer)
+	v.SetDefault("user_id", "")
+	v.SetDefault("output", defaultOutput)
+	v.SetDefault("server.timeout", defaultTimeout)
+
+	if path != "" {
+		v.SetConfigFile(path)
+
+		if err := v.ReadInConfig(); err != nil {
+			_, statErr := os.Stat(path)
+
+			if explicit || statErr == nil {
+				return nil, fmt.Errorf("config: can not read %s: %w", path, err)
+			}
+		}
+	}
+
+	return &ctlConfig{
+		Server:  v.GetString("server.url"),
+		UserID:  v.GetString("user_id"),
+		Tenant:  v.GetString("tenant"),
+		Token:   v.GetString("token"),
+		Output:  v.GetString("output"),
+		Timeout: v.GetDuration("server.timeout"),
+	}, nil
+}
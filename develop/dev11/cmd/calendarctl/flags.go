@@ -0,0 +1,59 @@
+package main
+
+import (
+	"dev11/core"
+	"flag"
+	"strings"
+)
+
+// listFlag - флаг, который можно указать несколько раз или перечислить значения через запятую
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+
+	return nil
+}
+
+type labels struct {
+	tags     listFlag
+	category *string
+	color    *string
+}
+
+func labelFlags(fs *flag.FlagSet) *labels {
+	l := &labels{}
+	fs.Var(&l.tags, "tag", "event tag, can be repeated")
+	l.category = fs.String("category", "", "event category")
+	l.color = fs.String("color", "", "event color, #rgb or #rrggbb")
+
+	return l
+}
+
+type filterArgs struct {
+	tags     listFlag
+	category *string
+}
+
+func filterFlags(fs *flag.FlagSet) *filterArgs {
+	f := &filterArgs{}
+	fs.Var(&f.tags, "tag", "only events with this tag, can be repeated")
+	f.category = fs.String("category", "", "only events of this category")
+
+	return f
+}
+
+func (f *filterArgs) filter() core.EventFilter {
+	return core.EventFilter{
+		Tags:     f.tags,
+		Category: *f.category,
+	}
+}
@@ -0,0 +1,428 @@
+package main
+
+import (
+	"context"
+	"dev11/client"
+	"dev11/core"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+const dateFormat = "2006-01-02"
+
+type command struct {
+	name  string
+	usage string
+	run   func(c *ctl, args []string) error
+}
+
+var commands = []command{
+	{name: "add", usage: "add -date YYYY-MM-DD -text TEXT [-tag TAG]... [-category NAME] [-color #RRGGBB] [-warn-holidays]", run: (*ctl).add},
+	{name: "update", usage: "update -id ID -date YYYY-MM-DD -text TEXT [-tag TAG]... [-category NAME] [-color #RRGGBB]", run: (*ctl).update},
+	{name: "rm", usage: "rm -id ID", run: (*ctl).remove},
+	{name: "day", usage: "day [-date YYYY-MM-DD] [-tag TAG]... [-category NAME]", run: (*ctl).day},
+	{name: "week", usage: "week [-since YYYY-MM-DD] [-tag TAG]... [-category NAME]", run: (*ctl).week},
+	{name: "month", usage: "month [-since YYYY-MM-DD] [-tag TAG]... [-category NAME]", run: (*ctl).month},
+	{name: "tags", usage: "tags", run: (*ctl).tags},
+	{name: "workdays", usage: "workdays -from YYYY-MM-DD (-to YYYY-MM-DD | -add N)", run: (*ctl).workdays},
+	{name: "export", usage: "export [-period day|week|month] [-since YYYY-MM-DD] [-tag TAG]... [-category NAME] [-format ics|json] [-file PATH]", run: (*ctl).export},
+}
+
+// ctl - всё, что нужно командам: клиент, конфиг и куда писать результат
+type ctl struct {
+	api  *client.Client
+	conf *ctlConfig
+	out  io.Writer
+	now  func() time.Time
+	ctx  context.Context
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "calendarctl:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("calendarctl", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to config file (default $"+configEnv+" or ~/.config/calendarctl/config.yml)")
+	server := fs.String("server", "", "calendar server url, overrides config")
+	userID := fs.String("user", "", "user id, overrides config")
+	tenant := fs.String("tenant", "", "tenant id, overrides config")
+	output := fs.String("o", "", "output format: table or json, overrides config")
+	fs.Usage = func() { usage(fs) }
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		usage(fs)
+		return errors.New("command is required")
+	}
+
+	path, explicit := *configPath, *configPath != ""
+	if !explicit {
+		path = defaultConfigPath()
+	}
+
+	conf, err := loadConfig(path, explicit)
+	if err != nil {
+		return err
+	}
+
+	if *server != "" {
+		conf.Server = *server
+	}
+	if *userID != "" {
+		conf.UserID = *userID
+	}
+	if *output != "" {
+		conf.Output = *output
+	}
+	if *tenant != "" {
+		conf.Tenant = *tenant
+	}
+
+	if conf.Output != outputTable && conf.Output != outputJSON {
+		return fmt.Errorf("unknown output format %q", conf.Output)
+	}
+
+	api, err := client.NewClient(conf.Server, &http.Client{Timeout: conf.Timeout})
+	if err != nil {
+		return err
+	}
+
+	if conf.Tenant != "" {
+		api = api.WithTenant(conf.Tenant)
+	}
+	if conf.Token != "" {
+		api = api.WithToken(conf.Token)
+	}
+
+	c := &ctl{
+		api:  api,
+		conf: conf,
+		out:  out,
+		now:  time.Now,
+		ctx:  context.Background(),
+	}
+
+	name := fs.Arg(0)
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if conf.UserID == "" {
+				return errors.New("user id is not set: use -user or user_id in config")
+			}
+
+			return cmd.run(c, fs.Args()[1:])
+		}
+	}
+
+	usage(fs)
+
+	return fmt.Errorf("unknown command %q", name)
+}
+
+func usage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintln(w, "usage: calendarctl [flags] <command> [command flags]")
+	fmt.Fprintln(w, "\ncommands:")
+
+	for _, cmd := range commands {
+		fmt.Fprintln(w, "  "+cmd.usage)
+	}
+
+	fmt.Fprintln(w, "\nflags:")
+	fs.PrintDefaults()
+}
+
+func (c *ctl) add(args []string) error {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	date := fs.String("date", "", "event date, YYYY-MM-DD")
+	text := fs.String("text", "", "event text")
+	labels := labelFlags(fs)
+	warnHolidays := fs.Bool("warn-holidays", false, "warn if the date is a holiday or a day off")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	day, err := parseDate("date", *date, false, c.now)
+	if err != nil {
+		return err
+	}
+
+	event := core.Event{
+		Text:     *text,
+		Date:     day,
+		UserID:   c.conf.UserID,
+		Tags:     labels.tags,
+		Category: *labels.category,
+		Color:    *labels.color,
+	}
+
+	if !*warnHolidays {
+		created, err := c.api.Create(c.ctx, event)
+		if err != nil {
+			return err
+		}
+
+		return printResult(c.out, c.conf.Output, fmt.Sprintf("event %s created", created.ID), created)
+	}
+
+	created, warnings, err := c.api.CreateWarnHolidays(c.ctx, event)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("event %s created", created.ID)
+	for _, w := range warnings {
+		message += "\nwarning: " + w
+	}
+
+	return printResult(c.out, c.conf.Output, message, struct {
+		core.Event
+		Warnings []string `json:"warnings,omitempty"`
+	}{created, warnings})
+}
+
+func (c *ctl) update(args []string) error {
+	fs := flag.NewFlagSet("update", flag.ContinueOnError)
+	id := fs.String("id", "", "event id")
+	date := fs.String("date", "", "event date, YYYY-MM-DD")
+	text := fs.String("text", "", "event text")
+	labels := labelFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *id == "" {
+		return errors.New("update: -id is required")
+	}
+
+	day, err := parseDate("date", *date, false, c.now)
+	if err != nil {
+		return err
+	}
+
+	event := core.Event{
+		ID:       *id,
+		Text:     *text,
+		Date:     day,
+		UserID:   c.conf.UserID,
+		Tags:     labels.tags,
+		Category: *labels.category,
+		Color:    *labels.color,
+	}
+
+	if err := c.api.Update(c.ctx, event); err != nil {
+		return err
+	}
+
+	return printResult(c.out, c.conf.Output, fmt.Sprintf("event %s updated", event.ID), event)
+}
+
+func (c *ctl) remove(args []string) error {
+	fs := flag.NewFlagSet("rm", flag.ContinueOnError)
+	id := fs.String("id", "", "event id")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *id == "" {
+		return errors.New("rm: -id is required")
+	}
+
+	if err := c.api.Delete(c.ctx, *id, c.conf.UserID); err != nil {
+		return err
+	}
+
+	return printResult(c.out, c.conf.Output, fmt.Sprintf("event %s deleted", *id), map[string]string{"id": *id})
+}
+
+func (c *ctl) day(args []string) error {
+	return c.listEvents("day", "date", args)
+}
+
+func (c *ctl) week(args []string) error {
+	return c.listEvents("week", "since", args)
+}
+
+func (c *ctl) month(args []string) error {
+	return c.listEvents("month", "since", args)
+}
+
+func (c *ctl) listEvents(period, dateFlag string, args []string) error {
+	fs := flag.NewFlagSet(period, flag.ContinueOnError)
+	date := fs.String(dateFlag, "", "YYYY-MM-DD, today by default")
+	filter := filterFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	events, err := c.fetch(period, dateFlag, *date, filter.filter())
+	if err != nil {
+		return err
+	}
+
+	return printEvents(c.out, c.conf.Output, events)
+}
+
+func (c *ctl) export(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	period := fs.String("period", "month", "day, week or month")
+	since := fs.String("since", "", "YYYY-MM-DD, today by default")
+	format := fs.String("format", exportICS, "ics or json")
+	file := fs.String("file", "", "write to file instead of stdout")
+	filter := filterFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *format != exportICS && *format != outputJSON {
+		return fmt.Errorf("export: unknown format %q", *format)
+	}
+
+	events, err := c.fetch(*period, "since", *since, filter.filter())
+	if err != nil {
+		return err
+	}
+
+	out := c.out
+	if *file != "" {
+		f, err := os.Create(*file)
+		if err != nil {
+			return fmt.Errorf("export: %w", err)
+		}
+		defer f.Close()
+
+		out = f
+	}
+
+	if *format == outputJSON {
+		err = writeJSON(out, events)
+	} else {
+		err = writeICS(out, events, c.now())
+	}
+
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	return nil
+}
+
+func (c *ctl) tags(args []string) error {
+	fs := flag.NewFlagSet("tags", flag.ContinueOnError)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	usage, err := c.api.Tags(c.ctx, c.conf.UserID)
+	if err != nil {
+		return err
+	}
+
+	return printTags(c.out, c.conf.Output, usage)
+}
+
+func (c *ctl) workdays(args []string) error {
+	fs := flag.NewFlagSet("workdays", flag.ContinueOnError)
+	from := fs.String("from", "", "YYYY-MM-DD, today by default")
+	to := fs.String("to", "", "count workdays in [from, to)")
+	add := fs.Int("add", 0, "date after N workdays since from")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	fromDate, err := parseDate("from", *from, true, c.now)
+	if err != nil {
+		return err
+	}
+
+	if *to != "" {
+		toDate, err := parseDate("to", *to, false, c.now)
+		if err != nil {
+			return err
+		}
+
+		n, err := c.api.WorkdaysBetween(c.ctx, fromDate, toDate)
+		if err != nil {
+			return err
+		}
+
+		return printResult(c.out, c.conf.Output, strconv.Itoa(n), map[string]int{"workdays": n})
+	}
+
+	date, err := c.api.AddWorkdays(c.ctx, fromDate, *add)
+	if err != nil {
+		return err
+	}
+
+	return printResult(c.out, c.conf.Output, date.Format(dateFormat), map[string]string{"date": date.Format(dateFormat)})
+}
+
+// fetch - события за период, пустой период - пустой список, чтобы таблица и JSON выводились без ошибки
+func (c *ctl) fetch(period, dateFlag, value string, filter core.EventFilter) ([]core.Event, error) {
+	date, err := parseDate(dateFlag, value, true, c.now)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []core.Event
+
+	switch period {
+	case "day":
+		events, err = c.api.EventsForDay(c.ctx, date, c.conf.UserID, filter)
+	case "week":
+		events, err = c.api.EventsForWeek(c.ctx, date, c.conf.UserID, filter)
+	case "month":
+		events, err = c.api.EventsForMonth(c.ctx, date, c.conf.UserID, filter)
+	default:
+		return nil, fmt.Errorf("unknown period %q", period)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if events == nil {
+		events = make([]core.Event, 0)
+	}
+
+	return events, nil
+}
+
+// parseDate - парсит дату в формате YYYY-MM-DD, при orToday пустое значение означает сегодня
+func parseDate(name, value string, orToday bool, now func() time.Time) (time.Time, error) {
+	if value == "" {
+		if !orToday {
+			return time.Time{}, fmt.Errorf("-%s is required", name)
+		}
+
+		y, m, d := now().Date()
+
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC), nil
+	}
+
+	date, err := time.Parse(dateFormat, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("bad -%s %q: expected YYYY-MM-DD", name, value)
+	}
+
+	return date, nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"dev11/core"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	exportICS   = "ics"
+)
+
+func printEvents(w io.Writer, format string, events []core.Event) error {
+	switch format {
+	case outputJSON:
+		return writeJSON(w, events)
+	case outputTable:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tDATE\tCATEGORY\tTAGS\tCOLOR\tTEXT")
+
+		for _, ev := range events {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				ev.ID, ev.Date.Format(dateFormat), dash(ev.Category), dash(strings.Join(ev.Tags, ",")), dash(ev.Color), ev.Text)
+		}
+
+		return tw.Flush()
+	default:
+		return fmt.Errorf("output: unknown format %q", format)
+	}
+}
+
+func printTags(w io.Writer, format string, usage []core.TagUsage) error {
+	switch format {
+	case outputJSON:
+		return writeJSON(w, usage)
+	case outputTable:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "TAG\tCOUNT")
+
+		for _, u := range usage {
+			fmt.Fprintf(tw, "%s\t%d\n", u.Tag, u.Count)
+		}
+
+		return tw.Flush()
+	default:
+		return fmt.Errorf("output: unknown format %q", format)
+	}
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+func printResult(w io.Writer, format, message string, v interface{}) error {
+	if format == outputJSON {
+		return writeJSON(w, v)
+	}
+
+	_, err := fmt.Fprintln(w, message)
+
+	return err
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+// writeICS - пишет события в формате iCalendar, каждое событие как VEVENT на весь день
+func writeICS(w io.Writer, events []core.Event, now time.Time) error {
+	var b strings.Builder
+
+	b.WriteString("BEGIN:VCALENDAR\r\n")
+	b.WriteString("VERSION:2.0\r\n")
+	b.WriteString("PRODID:-//dev11//calendarctl//EN\r\n")
+
+	for _, ev := range events {
+		b.WriteString("BEGIN:VEVENT\r\n")
+		fmt.Fprintf(&b, "UID:%s@dev11\r\n", ev.ID)
+		fmt.Fprintf(&b, "DTSTAMP:%s\r\n", now.UTC().Format("20060102T150405Z"))
+		fmt.Fprintf(&b, "DTSTART;VALUE=DATE:%s\r\n", ev.Date.Format("20060102"))
+		fmt.Fprintf(&b, "DTEND;VALUE=DATE:%s\r\n", ev.Date.AddDate(0, 0, 1).Format("20060102"))
+		fmt.Fprintf(&b, "SUMMARY:%s\r\n", escapeICS(ev.Text))
+
+		categories := ev.Tags
+		if ev.Category != "" {
+			categories = append([]string{ev.Category}, ev.Tags...)
+		}
+		if len(categories) > 0 {
+			escaped := make([]string, len(categories))
+			for i, c := range categories {
+				escaped[i] = escapeICS(c)
+			}
+			fmt.Fprintf(&b, "CATEGORIES:%s\r\n", strings.Join(escaped, ","))
+		}
+		b.WriteString("END:VEVENT\r\n")
+	}
+
+	b.WriteString("END:VCALENDAR\r\n")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func escapeICS(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+	return r.Replace(s)
+}
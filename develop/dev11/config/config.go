@@ -1,6 +1,7 @@
 package config
 
 import (
+	"dev11/core"
 	"github.com/spf13/viper"
 	"time"
 )
@@ -18,8 +19,21 @@ const (
 	writeTimeout      = 10 * time.Second
 )
 
+type HolidaysConfig struct {
+	Countries []string
+	Files     []string
+}
+
+type TenantsConfig struct {
+	AdminToken   string
+	AllowHeader  bool
+	DefaultQuota core.Quota
+}
+
 type Config struct {
-	ServConf *ServerConfig
+	ServConf     *ServerConfig
+	HolidaysConf *HolidaysConfig
+	TenantsConf  *TenantsConfig
 }
 
 func NewConfig() *Config {
@@ -30,6 +44,18 @@ func NewConfig() *Config {
 			ReadHeaderTimeout: readHeaderTimeout,
 			WriteTimeout:      writeTimeout,
 		},
+		HolidaysConf: &HolidaysConfig{
+			Countries: viper.GetStringSlice("holidays.countries"),
+			Files:     viper.GetStringSlice("holidays.files"),
+		},
+		TenantsConf: &TenantsConfig{
+			AdminToken:  viper.GetString("tenants.admin_token"),
+			AllowHeader: viper.GetBool("tenants.allow_header"),
+			DefaultQuota: core.Quota{
+				RequestsPerMinute: viper.GetInt("tenants.default_quota.requests_per_minute"),
+				MaxEvents:         viper.GetInt("tenants.default_quota.max_events"),
+			},
+		},
 	}
 }
 
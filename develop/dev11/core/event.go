@@ -1,16 +1,61 @@
 package core
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type Event struct {
-	ID     string    `json:"-"`
-	Text   string    `json:"text"`
-	Date   time.Time `json:"date"`
-	UserID string    `json:"user_id"`
+	ID       string    `json:"id"`
+	Text     string    `json:"text"`
+	Date     time.Time `json:"date"`
+	UserID   string    `json:"user_id"`
+	Tags     []string  `json:"tags,omitempty"`
+	Category string    `json:"category,omitempty"`
+	Color    string    `json:"color,omitempty"`
 }
 
 type EventResp struct {
-	ID   string    `json:"-"`
-	Text string    `json:"text"`
-	Date time.Time `json:"date"`
+	ID       string    `json:"id"`
+	Text     string    `json:"text"`
+	Date     time.Time `json:"date"`
+	Tags     []string  `json:"tags,omitempty"`
+	Category string    `json:"category,omitempty"`
+	Color    string    `json:"color,omitempty"`
+}
+
+// EventFilter - фильтр событий по тегам и категории, пустые поля не фильтруют
+type EventFilter struct {
+	Tags     []string
+	Category string
+}
+
+// Match - событие подходит, если у него есть все теги фильтра и совпадает категория
+func (f EventFilter) Match(ev Event) bool {
+	if f.Category != "" && !strings.EqualFold(f.Category, ev.Category) {
+		return false
+	}
+
+	for _, tag := range f.Tags {
+		if !ev.HasTag(tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (ev Event) HasTag(tag string) bool {
+	for _, t := range ev.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type TagUsage struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
 }
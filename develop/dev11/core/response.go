@@ -1,7 +1,8 @@
 package core
 
 type SuccessResponse struct {
-	Result interface{} `json:"result"`
+	Result   interface{} `json:"result"`
+	Warnings []string    `json:"warnings,omitempty"`
 }
 
 type ErrorResponse struct {
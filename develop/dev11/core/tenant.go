@@ -0,0 +1,17 @@
+package core
+
+const DefaultTenantID = "default"
+
+// Quota - ограничения тенанта, нулевое значение означает отсутствие ограничения
+type Quota struct {
+	RequestsPerMinute int `json:"requests_per_minute"`
+	MaxEvents         int `json:"max_events"`
+}
+
+type Tenant struct {
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	Token            string   `json:"token,omitempty"`
+	Quota            Quota    `json:"quota"`
+	HolidayCountries []string `json:"holiday_countries,omitempty"`
+}
@@ -1,6 +1,6 @@
 package core
 
 type User struct {
-	ID       string `json:"-"`
+	ID       string `json:"id"`
 	UserName string `json:"user_name"`
 }
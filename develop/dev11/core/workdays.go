@@ -0,0 +1,15 @@
+package core
+
+import "time"
+
+type WorkdaysCount struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Workdays int       `json:"workdays"`
+}
+
+type WorkdaysAdd struct {
+	From time.Time `json:"from"`
+	Add  int       `json:"add"`
+	Date time.Time `json:"date"`
+}
@@ -0,0 +1,170 @@
+package docs
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+// Spec - документ OpenAPI 3, описывающий API сервера
+func Spec() []byte {
+	return spec
+}
+
+type operation struct {
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+	Parameters  []struct {
+		Name        string `json:"name"`
+		In          string `json:"in"`
+		Required    bool   `json:"required"`
+		Description string `json:"description"`
+	} `json:"parameters"`
+	RequestBody *struct {
+		Content map[string]struct {
+			Schema struct {
+				Ref string `json:"$ref"`
+			} `json:"schema"`
+		} `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Ref         string `json:"$ref"`
+		Description string `json:"description"`
+	} `json:"responses"`
+}
+
+type document struct {
+	Info struct {
+		Title       string `json:"title"`
+		Version     string `json:"version"`
+		Description string `json:"description"`
+	} `json:"info"`
+	Paths map[string]map[string]operation `json:"paths"`
+}
+
+type pageOperation struct {
+	Method    string
+	Path      string
+	Op        operation
+	Body      string
+	Responses []pageResponse
+}
+
+type pageResponse struct {
+	Code        string
+	Description string
+}
+
+var page = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: 2em auto; color: #222; }
+.op { border: 1px solid #ddd; border-radius: 4px; margin: 1em 0; padding: 0.5em 1em; }
+.method { font-weight: bold; text-transform: uppercase; margin-right: 0.5em; }
+code { background: #f4f4f4; padding: 0 0.2em; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ddd; padding: 0.2em 0.5em; text-align: left; }
+</style>
+</head>
+<body>
+<h1>{{.Title}} <small>{{.Version}}</small></h1>
+<p>{{.Description}}</p>
+<p>Machine readable document: <a href="/openapi.json">/openapi.json</a></p>
+{{range .Operations}}
+<div class="op">
+<h3><span class="method">{{.Method}}</span><code>{{.Path}}</code></h3>
+<p>{{.Op.Summary}}</p>
+{{with .Op.Description}}<p>{{.}}</p>{{end}}
+{{if .Op.Parameters}}
+<table>
+<tr><th>Parameter</th><th>In</th><th>Required</th><th>Description</th></tr>
+{{range .Op.Parameters}}<tr><td><code>{{.Name}}</code></td><td>{{.In}}</td><td>{{.Required}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>
+{{end}}
+{{with .Body}}<p>Request body: <code>{{.}}</code></p>{{end}}
+<table>
+<tr><th>Status</th><th>Response</th></tr>
+{{range .Responses}}<tr><td>{{.Code}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>
+</div>
+{{end}}
+</body>
+</html>
+`))
+
+// Page - HTML страница документации, сгенерированная из Spec
+func Page() ([]byte, error) {
+	var doc document
+
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("docs: bad openapi document: %w", err)
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	ops := make([]pageOperation, 0, len(paths))
+	for _, path := range paths {
+		methods := make([]string, 0, len(doc.Paths[path]))
+		for m := range doc.Paths[path] {
+			methods = append(methods, m)
+		}
+		sort.Strings(methods)
+
+		for _, m := range methods {
+			op := doc.Paths[path][m]
+			pop := pageOperation{Method: m, Path: path, Op: op}
+
+			if op.RequestBody != nil {
+				for _, c := range op.RequestBody.Content {
+					pop.Body = strings.TrimPrefix(c.Schema.Ref, "#/components/schemas/")
+				}
+			}
+
+			codes := make([]string, 0, len(op.Responses))
+			for code := range op.Responses {
+				codes = append(codes, code)
+			}
+			sort.Strings(codes)
+
+			for _, code := range codes {
+				r := op.Responses[code]
+				desc := r.Description
+				if desc == "" {
+					desc = strings.TrimPrefix(r.Ref, "#/components/responses/")
+				}
+				pop.Responses = append(pop.Responses, pageResponse{Code: code, Description: desc})
+			}
+
+			ops = append(ops, pop)
+		}
+	}
+
+	var buf bytes.Buffer
+
+	err := page.Execute(&buf, map[string]interface{}{
+		"Title":       doc.Info.Title,
+		"Version":     doc.Info.Version,
+		"Description": doc.Info.Description,
+		"Operations":  ops,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("docs: can not render page: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
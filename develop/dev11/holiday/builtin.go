@@ -0,0 +1,120 @@
+package holiday
+
+import (
+	"sort"
+	"time"
+)
+
+// rule - ежегодное правило, возвращающее праздники за год
+type rule func(year int) []Holiday
+
+// builtin - встроенные таблицы государственных праздников без учёта переносов выходных
+var builtin = map[string][]rule{
+	"RU": {
+		fixedRange(time.January, 1, 6, "Новогодние каникулы"),
+		fixed(time.January, 7, "Рождество Христово"),
+		fixed(time.January, 8, "Новогодние каникулы"),
+		fixed(time.February, 23, "День защитника Отечества"),
+		fixed(time.March, 8, "Международный женский день"),
+		fixed(time.May, 1, "Праздник Весны и Труда"),
+		fixed(time.May, 9, "День Победы"),
+		fixed(time.June, 12, "День России"),
+		fixed(time.November, 4, "День народного единства"),
+	},
+	"US": {
+		fixed(time.January, 1, "New Year's Day"),
+		nthWeekday(time.January, time.Monday, 3, "Martin Luther King Jr. Day"),
+		nthWeekday(time.February, time.Monday, 3, "Washington's Birthday"),
+		nthWeekday(time.May, time.Monday, -1, "Memorial Day"),
+		fixed(time.June, 19, "Juneteenth National Independence Day"),
+		fixed(time.July, 4, "Independence Day"),
+		nthWeekday(time.September, time.Monday, 1, "Labor Day"),
+		nthWeekday(time.October, time.Monday, 2, "Columbus Day"),
+		fixed(time.November, 11, "Veterans Day"),
+		nthWeekday(time.November, time.Thursday, 4, "Thanksgiving Day"),
+		fixed(time.December, 25, "Christmas Day"),
+	},
+	"DE": {
+		fixed(time.January, 1, "Neujahr"),
+		easterOffset(-2, "Karfreitag"),
+		easterOffset(1, "Ostermontag"),
+		fixed(time.May, 1, "Tag der Arbeit"),
+		easterOffset(39, "Christi Himmelfahrt"),
+		easterOffset(50, "Pfingstmontag"),
+		fixed(time.October, 3, "Tag der Deutschen Einheit"),
+		fixed(time.December, 25, "1. Weihnachtstag"),
+		fixed(time.December, 26, "2. Weihnachtstag"),
+	},
+}
+
+// Countries - коды стран со встроенными таблицами праздников
+func Countries() []string {
+	codes := make([]string, 0, len(builtin))
+	for code := range builtin {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	return codes
+}
+
+func fixed(month time.Month, d int, name string) rule {
+	return fixedRange(month, d, d, name)
+}
+
+func fixedRange(month time.Month, from, to int, name string) rule {
+	return func(year int) []Holiday {
+		hs := make([]Holiday, 0, to-from+1)
+		for d := from; d <= to; d++ {
+			hs = append(hs, Holiday{Date: time.Date(year, month, d, 0, 0, 0, 0, time.UTC), Name: name})
+		}
+
+		return hs
+	}
+}
+
+// nthWeekday - n-й день недели месяца, при n = -1 последний
+func nthWeekday(month time.Month, wd time.Weekday, n int, name string) rule {
+	return func(year int) []Holiday {
+		var date time.Time
+
+		if n > 0 {
+			first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+			shift := (int(wd) - int(first.Weekday()) + 7) % 7
+			date = first.AddDate(0, 0, shift+7*(n-1))
+		} else {
+			last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+			shift := (int(last.Weekday()) - int(wd) + 7) % 7
+			date = last.AddDate(0, 0, -shift)
+		}
+
+		return []Holiday{{Date: date, Name: name}}
+	}
+}
+
+// easterOffset - праздник через offset дней после католической Пасхи
+func easterOffset(offset int, name string) rule {
+	return func(year int) []Holiday {
+		return []Holiday{{Date: easter(year).AddDate(0, 0, offset), Name: name}}
+	}
+}
+
+// easter - дата католической Пасхи по григорианскому календарю (алгоритм Гаусса в форме Meeus/Jones/Butcher)
+func easter(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	dd := (h+l-7*m+114)%31 + 1
+
+	return time.Date(year, time.Month(month), dd, 0, 0, 0, 0, time.UTC)
+}
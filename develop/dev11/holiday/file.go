@@ -0,0 +1,211 @@
+package holiday
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// AddFile - добавляет праздники из файла, формат определяется по расширению: .ics или .json
+func (c *Calendar) AddFile(path string) error {
+	f, err := os.Open(path)
+
+	if err != nil {
+		return fmt.Errorf("holiday: can not open file: %w", err)
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ics", ".ical":
+		err = c.ReadICS(f)
+	case ".json":
+		err = c.ReadJSON(f)
+	default:
+		return fmt.Errorf("holiday: unknown file format %s, expected .ics or .json", path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("holiday: %s: %w", path, err)
+	}
+
+	return nil
+}
+
+type jsonCalendar struct {
+	Weekend  []string `json:"weekend"`
+	Holidays []struct {
+		Date string `json:"date"`
+		Name string `json:"name"`
+	} `json:"holidays"`
+}
+
+/*
+ReadJSON - читает праздники в формате
+{"weekend": ["saturday", "sunday"], "holidays": [{"date": "2024-01-01", "name": "New Year"}]}
+поле weekend необязательное
+*/
+func (c *Calendar) ReadJSON(r io.Reader) error {
+	var jc jsonCalendar
+
+	if err := json.NewDecoder(r).Decode(&jc); err != nil {
+		return fmt.Errorf("bad json: %w", err)
+	}
+
+	holidays := make([]Holiday, 0, len(jc.Holidays))
+	for _, h := range jc.Holidays {
+		date, err := time.Parse(dateFormat, h.Date)
+
+		if err != nil {
+			return fmt.Errorf("bad holiday date %q: expected YYYY-MM-DD", h.Date)
+		}
+		holidays = append(holidays, Holiday{Date: date, Name: h.Name})
+	}
+
+	if len(jc.Weekend) > 0 {
+		days := make([]time.Weekday, 0, len(jc.Weekend))
+		for _, name := range jc.Weekend {
+			wd, err := parseWeekday(name)
+
+			if err != nil {
+				return err
+			}
+			days = append(days, wd)
+		}
+		c.SetWeekend(days)
+	}
+
+	for _, h := range holidays {
+		c.Add(h)
+	}
+
+	return nil
+}
+
+// ReadICS - читает праздники из VEVENT календаря iCalendar, многодневные события дают праздник на каждый день
+func (c *Calendar) ReadICS(r io.Reader) error {
+	lines, err := unfoldICS(r)
+
+	if err != nil {
+		return err
+	}
+
+	var (
+		inEvent    bool
+		start, end time.Time
+		summary    string
+	)
+
+	for i, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+
+		if !ok {
+			continue
+		}
+
+		prop, _, _ := strings.Cut(name, ";")
+
+		switch strings.ToUpper(prop) {
+		case "BEGIN":
+			if strings.EqualFold(value, "VEVENT") {
+				inEvent = true
+				start, end, summary = time.Time{}, time.Time{}, ""
+			}
+		case "DTSTART":
+			if inEvent {
+				if start, err = parseICSDate(value); err != nil {
+					return fmt.Errorf("line %d: %w", i+1, err)
+				}
+			}
+		case "DTEND":
+			if inEvent {
+				if end, err = parseICSDate(value); err != nil {
+					return fmt.Errorf("line %d: %w", i+1, err)
+				}
+			}
+		case "SUMMARY":
+			if inEvent {
+				summary = unescapeICS(value)
+			}
+		case "END":
+			if !strings.EqualFold(value, "VEVENT") || !inEvent {
+				continue
+			}
+			inEvent = false
+
+			if start.IsZero() {
+				return fmt.Errorf("line %d: event without DTSTART", i+1)
+			}
+
+			if !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+
+			for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+				c.Add(Holiday{Date: d, Name: summary})
+			}
+		}
+	}
+
+	return nil
+}
+
+// unfoldICS - склеивает перенесённые строки: строка, начинающаяся с пробела или табуляции, продолжает предыдущую
+func unfoldICS(r io.Reader) ([]string, error) {
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("can not read ics: %w", err)
+	}
+
+	return lines, nil
+}
+
+// parseICSDate - берёт дату из значений вида 20240101 или 20240101T100000Z
+func parseICSDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("bad date %q", value)
+	}
+
+	date, err := time.Parse("20060102", value[:8])
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("bad date %q", value)
+	}
+
+	return date, nil
+}
+
+func unescapeICS(s string) string {
+	r := strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+	return r.Replace(s)
+}
+
+func parseWeekday(name string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+
+		if strings.EqualFold(name, full) || strings.EqualFold(name, full[:3]) {
+			return d, nil
+		}
+	}
+
+	return 0, fmt.Errorf("bad weekday %q", name)
+}
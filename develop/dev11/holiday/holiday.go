@@ -0,0 +1,214 @@
+package holiday
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const dateFormat = "2006-01-02"
+
+type Holiday struct {
+	Date time.Time `json:"date"`
+	Name string    `json:"name"`
+}
+
+// Calendar - производственный календарь: выходные дни недели, ежегодные праздники и праздники на конкретные даты
+type Calendar struct {
+	mu      sync.Mutex
+	weekend map[time.Weekday]bool
+	rules   []rule
+	dates   map[string]string
+	years   map[int]map[string]string
+}
+
+func NewCalendar() *Calendar {
+	return &Calendar{
+		weekend: map[time.Weekday]bool{
+			time.Saturday: true,
+			time.Sunday:   true,
+		},
+		dates: make(map[string]string),
+		years: make(map[int]map[string]string),
+	}
+}
+
+// Load - создаёт календарь из встроенных таблиц стран и файлов ICS/JSON
+func Load(countries []string, files []string) (*Calendar, error) {
+	cal := NewCalendar()
+
+	for _, country := range countries {
+		if err := cal.AddCountry(country); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range files {
+		if err := cal.AddFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	return cal, nil
+}
+
+// AddCountry - добавляет праздники из встроенной таблицы страны
+func (c *Calendar) AddCountry(code string) error {
+	rules, ok := builtin[strings.ToUpper(code)]
+
+	if !ok {
+		return fmt.Errorf("holiday: unknown country %q, available: %s", code, strings.Join(Countries(), ", "))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rules = append(c.rules, rules...)
+	c.years = make(map[int]map[string]string)
+
+	return nil
+}
+
+// Add - добавляет праздник на конкретную дату
+func (c *Calendar) Add(h Holiday) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.dates[h.Date.Format(dateFormat)] = h.Name
+}
+
+// SetWeekend - задаёт дни недели, которые считаются выходными
+func (c *Calendar) SetWeekend(days []time.Weekday) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.weekend = make(map[time.Weekday]bool, len(days))
+	for _, d := range days {
+		c.weekend[d] = true
+	}
+}
+
+// HolidayOn - праздник на дату, если он есть
+func (c *Calendar) HolidayOn(date time.Time) (Holiday, bool) {
+	key := date.Format(dateFormat)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if name, ok := c.dates[key]; ok {
+		return Holiday{Date: day(date), Name: name}, true
+	}
+
+	if name, ok := c.yearHolidays(date.Year())[key]; ok {
+		return Holiday{Date: day(date), Name: name}, true
+	}
+
+	return Holiday{}, false
+}
+
+// IsWorkday - дата не выходной и не праздник
+func (c *Calendar) IsWorkday(date time.Time) bool {
+	c.mu.Lock()
+	weekend := c.weekend[date.Weekday()]
+	c.mu.Unlock()
+
+	if weekend {
+		return false
+	}
+
+	_, holiday := c.HolidayOn(date)
+
+	return !holiday
+}
+
+// WorkdaysBetween - количество рабочих дней в полуинтервале [from, to), отрицательное если to раньше from
+func (c *Calendar) WorkdaysBetween(from, to time.Time) int {
+	from, to = day(from), day(to)
+	sign := 1
+
+	if to.Before(from) {
+		from, to = to, from
+		sign = -1
+	}
+
+	count := 0
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		if c.IsWorkday(d) {
+			count++
+		}
+	}
+
+	return sign * count
+}
+
+// AddWorkdays - дата через n рабочих дней после from (при отрицательном n - до from)
+func (c *Calendar) AddWorkdays(from time.Time, n int) time.Time {
+	d := day(from)
+	step := 1
+
+	if n < 0 {
+		step, n = -1, -n
+	}
+
+	for n > 0 {
+		d = d.AddDate(0, 0, step)
+		if c.IsWorkday(d) {
+			n--
+		}
+	}
+
+	return d
+}
+
+// Holidays - все праздники календаря за год, отсортированные по дате
+func (c *Calendar) Holidays(year int) []Holiday {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	names := make(map[string]string)
+	for key, name := range c.yearHolidays(year) {
+		names[key] = name
+	}
+	for key, name := range c.dates {
+		if strings.HasPrefix(key, fmt.Sprintf("%04d-", year)) {
+			names[key] = name
+		}
+	}
+
+	res := make([]Holiday, 0, len(names))
+	for key, name := range names {
+		date, _ := time.Parse(dateFormat, key)
+		res = append(res, Holiday{Date: date, Name: name})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Date.Before(res[j].Date)
+	})
+
+	return res
+}
+
+// yearHolidays - праздники по правилам за год, вызывать под c.mu
+func (c *Calendar) yearHolidays(year int) map[string]string {
+	if hs, ok := c.years[year]; ok {
+		return hs
+	}
+
+	hs := make(map[string]string)
+	for _, r := range c.rules {
+		for _, h := range r(year) {
+			hs[h.Date.Format(dateFormat)] = h.Name
+		}
+	}
+	c.years[year] = hs
+
+	return hs
+}
+
+func day(t time.Time) time.Time {
+	y, m, d := t.Date()
+
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
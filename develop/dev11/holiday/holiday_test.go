@@ -0,0 +1,129 @@
+package holiday
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, err := time.Parse(dateFormat, s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func TestBuiltinHolidays(t *testing.T) {
+	tests := []struct {
+		country string
+		date    string
+		want    string
+	}{
+		{country: "US", date: "2024-11-28", want: "Thanksgiving Day"},
+		{country: "US", date: "2024-05-27", want: "Memorial Day"},
+		{country: "US", date: "2025-01-20", want: "Martin Luther King Jr. Day"},
+		{country: "DE", date: "2024-03-29", want: "Karfreitag"},
+		{country: "DE", date: "2025-06-09", want: "Pfingstmontag"},
+		{country: "ru", date: "2024-01-03", want: "Новогодние каникулы"},
+		{country: "RU", date: "2024-06-12", want: "День России"},
+		{country: "RU", date: "2024-06-13", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.country+" "+tt.date, func(t *testing.T) {
+			cal, err := Load([]string{tt.country}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			h, ok := cal.HolidayOn(date(tt.date))
+			if ok != (tt.want != "") || h.Name != tt.want {
+				t.Errorf("HolidayOn(%s) = %q, %v, want %q", tt.date, h.Name, ok, tt.want)
+			}
+		})
+	}
+
+	if _, err := Load([]string{"XX"}, nil); err == nil {
+		t.Error("Load() with unknown country: expected error")
+	}
+}
+
+func TestWorkdayArithmetic(t *testing.T) {
+	cal, err := Load([]string{"RU"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		got  func() interface{}
+		want interface{}
+	}{
+		{
+			name: "january has 17 workdays",
+			got:  func() interface{} { return cal.WorkdaysBetween(date("2024-01-01"), date("2024-02-01")) },
+			want: 17,
+		},
+		{
+			name: "reversed interval is negative",
+			got:  func() interface{} { return cal.WorkdaysBetween(date("2024-02-01"), date("2024-01-01")) },
+			want: -17,
+		},
+		{
+			name: "add skips weekend and holiday",
+			got:  func() interface{} { return cal.AddWorkdays(date("2024-02-21"), 2).Format(dateFormat) },
+			want: "2024-02-26",
+		},
+		{
+			name: "add backwards",
+			got:  func() interface{} { return cal.AddWorkdays(date("2024-01-09"), -1).Format(dateFormat) },
+			want: "2023-12-29",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.got(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFiles(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20240715\r\n" +
+		"DTEND;VALUE=DATE:20240717\r\n" +
+		"SUMMARY:Company\r\n  retreat\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	cal := NewCalendar()
+	if err := cal.ReadICS(strings.NewReader(ics)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range []string{"2024-07-15", "2024-07-16"} {
+		if h, ok := cal.HolidayOn(date(d)); !ok || h.Name != "Company retreat" {
+			t.Errorf("ics: HolidayOn(%s) = %q, %v", d, h.Name, ok)
+		}
+	}
+	if _, ok := cal.HolidayOn(date("2024-07-17")); ok {
+		t.Error("ics: DTEND must be exclusive")
+	}
+
+	js := `{"weekend": ["fri", "saturday"], "holidays": [{"date": "2024-03-20", "name": "Nowruz"}]}`
+	cal = NewCalendar()
+	if err := cal.ReadJSON(strings.NewReader(js)); err != nil {
+		t.Fatal(err)
+	}
+
+	if cal.IsWorkday(date("2024-03-20")) || cal.IsWorkday(date("2024-03-22")) || !cal.IsWorkday(date("2024-03-24")) {
+		t.Error("json: weekend or holidays are not applied")
+	}
+
+	if err := cal.ReadJSON(strings.NewReader(`{"holidays": [{"date": "20.03.2024"}]}`)); err == nil {
+		t.Error("json: expected error for bad date")
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter - ограничитель запросов по алгоритму token bucket, у каждого ключа своя корзина
+type RateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow - можно ли выполнить запрос по ключу при лимите perMinute запросов в минуту, 0 - без лимита
+func (rl *RateLimiter) Allow(key string, perMinute int) bool {
+	if perMinute <= 0 {
+		return true
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	limit := float64(perMinute)
+	b, ok := rl.buckets[key]
+
+	if !ok {
+		b = &bucket{tokens: limit, last: now}
+		rl.buckets[key] = b
+	}
+
+	b.tokens += now.Sub(b.last).Minutes() * limit
+	if b.tokens > limit {
+		b.tokens = limit
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+
+	return true
+}
@@ -0,0 +1,17 @@
+package middleware
+
+import "context"
+
+type tenantKey struct{}
+
+// WithTenant - кладёт id тенанта запроса в контекст
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantID - id тенанта из контекста, пустая строка если тенант не определён
+func TenantID(ctx context.Context) string {
+	id, _ := ctx.Value(tenantKey{}).(string)
+
+	return id
+}
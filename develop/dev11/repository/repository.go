@@ -1 +1,219 @@
 package repository
+
+import (
+	"crypto/subtle"
+	"dev11/core"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrTenantNotFound = errors.New("tenant not found")
+	ErrTenantExists   = errors.New("tenant already exists")
+	ErrEventNotFound  = errors.New("event not found")
+)
+
+// tenantData - данные одного тенанта, хранятся отдельно от данных других тенантов
+type tenantData struct {
+	tenant core.Tenant
+	users  map[string]core.User
+	events []core.Event
+}
+
+/*
+Storage - хранилище в памяти, разделённое по тенантам
+все методы работы с пользователями и событиями принимают id тенанта и видят только его данные
+*/
+type Storage struct {
+	mu      sync.RWMutex
+	tenants map[string]*tenantData
+}
+
+func NewStorage() *Storage {
+	return &Storage{
+		tenants: make(map[string]*tenantData),
+	}
+}
+
+func (s *Storage) CreateTenant(tenant core.Tenant, users []core.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tenants[tenant.ID]; ok {
+		return fmt.Errorf("repository: %w: %s", ErrTenantExists, tenant.ID)
+	}
+
+	data := &tenantData{
+		tenant: tenant,
+		users:  make(map[string]core.User, len(users)),
+		events: make([]core.Event, 0),
+	}
+	for _, u := range users {
+		data.users[u.ID] = u
+	}
+	s.tenants[tenant.ID] = data
+
+	return nil
+}
+
+func (s *Storage) Tenant(id string) (core.Tenant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.tenants[id]
+
+	if !ok {
+		return core.Tenant{}, fmt.Errorf("repository: %w: %s", ErrTenantNotFound, id)
+	}
+
+	return data.tenant, nil
+}
+
+// TenantByToken - ищет тенанта по токену, токены сравниваются за постоянное время, чтобы их нельзя было подобрать по времени ответа
+func (s *Storage) TenantByToken(token string) (core.Tenant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, data := range s.tenants {
+		if token != "" && subtle.ConstantTimeCompare([]byte(data.tenant.Token), []byte(token)) == 1 {
+			return data.tenant, nil
+		}
+	}
+
+	return core.Tenant{}, fmt.Errorf("repository: %w: bad token", ErrTenantNotFound)
+}
+
+func (s *Storage) Tenants() []core.Tenant {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tenants := make([]core.Tenant, 0, len(s.tenants))
+	for _, data := range s.tenants {
+		tenants = append(tenants, data.tenant)
+	}
+
+	return tenants
+}
+
+func (s *Storage) SetQuota(id string, quota core.Quota) (core.Tenant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.tenants[id]
+
+	if !ok {
+		return core.Tenant{}, fmt.Errorf("repository: %w: %s", ErrTenantNotFound, id)
+	}
+
+	data.tenant.Quota = quota
+
+	return data.tenant, nil
+}
+
+func (s *Storage) ExistUser(tenantID, userID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.tenants[tenantID]
+	if !ok {
+		return false
+	}
+
+	_, ok = data.users[userID]
+
+	return ok
+}
+
+// AddEvent - добавляет событие, если у тенанта не превышен лимит событий
+func (s *Storage) AddEvent(tenantID string, event core.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.tenants[tenantID]
+
+	if !ok {
+		return fmt.Errorf("repository: %w: %s", ErrTenantNotFound, tenantID)
+	}
+
+	if max := data.tenant.Quota.MaxEvents; max > 0 && len(data.events) >= max {
+		return fmt.Errorf("repository: tenant %s reached events quota %d", tenantID, max)
+	}
+
+	data.events = append(data.events, event)
+
+	return nil
+}
+
+func (s *Storage) UpdateEvent(tenantID string, event core.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.tenants[tenantID]
+
+	if !ok {
+		return fmt.Errorf("repository: %w: %s", ErrTenantNotFound, tenantID)
+	}
+
+	i := data.find(event.ID, event.UserID)
+
+	if i == -1 {
+		return fmt.Errorf("repository: %w: id %s, user id %s", ErrEventNotFound, event.ID, event.UserID)
+	}
+
+	data.events[i] = event
+
+	return nil
+}
+
+func (s *Storage) DeleteEvent(tenantID, evID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.tenants[tenantID]
+
+	if !ok {
+		return fmt.Errorf("repository: %w: %s", ErrTenantNotFound, tenantID)
+	}
+
+	i := data.find(evID, userID)
+
+	if i == -1 {
+		return fmt.Errorf("repository: %w: id %s, user id %s", ErrEventNotFound, evID, userID)
+	}
+
+	data.events = append(data.events[:i], data.events[i+1:]...)
+
+	return nil
+}
+
+// Events - события тенанта, для которых match вернул true
+func (s *Storage) Events(tenantID string, match func(core.Event) bool) []core.Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := make([]core.Event, 0)
+
+	data, ok := s.tenants[tenantID]
+	if !ok {
+		return events
+	}
+
+	for _, ev := range data.events {
+		if match(ev) {
+			events = append(events, ev)
+		}
+	}
+
+	return events
+}
+
+func (td *tenantData) find(id, userID string) int {
+	for i, ev := range td.events {
+		if ev.ID == id && ev.UserID == userID {
+			return i
+		}
+	}
+
+	return -1
+}
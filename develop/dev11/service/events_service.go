@@ -1,146 +1,180 @@
 package service
 
 import (
+	"crypto/rand"
 	"dev11/core"
+	"encoding/hex"
 	"fmt"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
-var Events = make([]core.Event, 0)
-var Users = []core.User{
-	core.User{
-		ID:       "3",
-		UserName: "vlad",
-	},
+const (
+	maxTags        = 10
+	maxTagLen      = 32
+	maxCategoryLen = 64
+)
+
+var (
+	tagRe   = regexp.MustCompile(fmt.Sprintf(`^[\p{L}\p{N}_-]{1,%d}$`, maxTagLen))
+	colorRe = regexp.MustCompile(`^#([0-9a-f]{3}|[0-9a-f]{6})$`)
+)
+
+// EventsRepo - хранилище событий, разделённое по тенантам
+type EventsRepo interface {
+	ExistUser(tenantID, userID string) bool
+	AddEvent(tenantID string, event core.Event) error
+	UpdateEvent(tenantID string, event core.Event) error
+	DeleteEvent(tenantID, evID, userID string) error
+	Events(tenantID string, match func(core.Event) bool) []core.Event
 }
 
-type EventsService struct{}
+type EventsService struct {
+	repo EventsRepo
+}
 
-func NewEventsService() *EventsService {
-	return &EventsService{}
+func NewEventsService(repo EventsRepo) *EventsService {
+	return &EventsService{
+		repo: repo,
+	}
 }
 
-func (es *EventsService) Create(event core.Event) error {
+func (es *EventsService) Create(tenantID string, event core.Event) (core.Event, error) {
+	event = normalizeEvent(event)
+
 	if validate := es.validateEvent(event); validate != nil {
-		return validate
+		return core.Event{}, validate
 	}
 
-	Events = append(Events, event)
+	if !es.repo.ExistUser(tenantID, event.UserID) {
+		return core.Event{}, fmt.Errorf("create: user not exist")
+	}
 
-	return nil
+	id, err := newEventID()
+
+	if err != nil {
+		return core.Event{}, err
+	}
+
+	event.ID = id
+
+	if err := es.repo.AddEvent(tenantID, event); err != nil {
+		return core.Event{}, fmt.Errorf("create: %w", err)
+	}
+
+	return event, nil
 }
 
-func (es *EventsService) Update(event core.Event) error {
+func (es *EventsService) Update(tenantID string, event core.Event) error {
+	event = normalizeEvent(event)
+
 	if validate := es.validateEvent(event); validate != nil {
 		return validate
 	}
 
-	eventKey := es.foundEvent(event.ID, event.UserID)
+	if !es.repo.ExistUser(tenantID, event.UserID) {
+		return fmt.Errorf("update: user not exist")
+	}
 
-	if eventKey == -1 {
+	if err := es.repo.UpdateEvent(tenantID, event); err != nil {
 		return fmt.Errorf("event: event by id %s and user id %s is not found", event.ID, event.UserID)
 	}
 
-	Events[eventKey] = event
-
 	return nil
 }
 
-func (es *EventsService) Delete(evID, userID string) error {
-	if !es.existUser(userID) {
+func (es *EventsService) Delete(tenantID, evID, userID string) error {
+	if !es.repo.ExistUser(tenantID, userID) {
 		return fmt.Errorf("delete: user not exist")
 	}
 
-	eventKey := es.foundEvent(evID, userID)
-
-	if eventKey == -1 {
+	if err := es.repo.DeleteEvent(tenantID, evID, userID); err != nil {
 		return fmt.Errorf("event: event by id %s and user id %s is not found", evID, userID)
 	}
 
-	Events = append(Events[:eventKey], Events[eventKey+1:]...)
-
 	return nil
 }
 
-func (es *EventsService) EventByDay(day time.Time, userID string) ([]core.Event, error) {
-	if !es.existUser(userID) {
-		return nil, fmt.Errorf("delete: user not exist")
-	}
-
-	eventsByDay := make([]core.Event, 0)
-	for _, ev := range Events {
-		if ev.Date == day && ev.UserID == userID {
-			eventsByDay = append(eventsByDay, ev)
-		}
-	}
-
-	if len(eventsByDay) == 0 {
-		return nil, fmt.Errorf("event: have not events by day %s", day)
+func (es *EventsService) EventByDay(tenantID string, day time.Time, userID string, filter core.EventFilter) ([]core.Event, error) {
+	if !es.repo.ExistUser(tenantID, userID) {
+		return nil, fmt.Errorf("event: user not exist")
 	}
 
-	return eventsByDay, nil
+	return es.eventsInRange(tenantID, day, day.AddDate(0, 0, 1), userID, filter), nil
 }
 
-func (es *EventsService) EventByWeek(since time.Time, userID string) ([]core.Event, error) {
-	if !es.existUser(userID) {
-		return nil, fmt.Errorf("delete: user not exist")
+func (es *EventsService) EventByWeek(tenantID string, since time.Time, userID string, filter core.EventFilter) ([]core.Event, error) {
+	if !es.repo.ExistUser(tenantID, userID) {
+		return nil, fmt.Errorf("event: user not exist")
 	}
 
-	eventsByWeek := make([]core.Event, 0)
-	forDay := since.AddDate(0, 0, since.Day()+7)
+	return es.eventsInRange(tenantID, since, since.AddDate(0, 0, 7), userID, filter), nil
+}
 
-	for _, ev := range Events {
-		if (ev.Date.After(since) && ev.Date.Before(forDay) || ev.Date == since) && ev.UserID == userID {
-			eventsByWeek = append(eventsByWeek, ev)
-		}
-	}
-	if len(eventsByWeek) == 0 {
-		return nil, fmt.Errorf("event: have not events by week since %s", since)
+func (es *EventsService) EventByMonth(tenantID string, since time.Time, userID string, filter core.EventFilter) ([]core.Event, error) {
+	if !es.repo.ExistUser(tenantID, userID) {
+		return nil, fmt.Errorf("event: user not exist")
 	}
 
-	return eventsByWeek, nil
+	return es.eventsInRange(tenantID, since, since.AddDate(0, 1, 0), userID, filter), nil
 }
 
-func (es *EventsService) EventByMonth(since time.Time, userID string) ([]core.Event, error) {
-	if !es.existUser(userID) {
-		return nil, fmt.Errorf("delete: user not exist")
+// TagsUsage - количество событий пользователя с каждым тегом, самые частые теги первыми
+func (es *EventsService) TagsUsage(tenantID, userID string) ([]core.TagUsage, error) {
+	if !es.repo.ExistUser(tenantID, userID) {
+		return nil, fmt.Errorf("tags: user not exist")
 	}
 
-	eventsByWeek := make([]core.Event, 0)
-	forDay := since.AddDate(0, int(since.Month())+30, 0)
+	counts := make(map[string]int)
+
+	events := es.repo.Events(tenantID, func(ev core.Event) bool {
+		return ev.UserID == userID
+	})
 
-	for _, ev := range Events {
-		if (ev.Date.After(since) && ev.Date.Before(forDay) || ev.Date == since) && ev.UserID == userID {
-			eventsByWeek = append(eventsByWeek, ev)
+	for _, ev := range events {
+		for _, tag := range ev.Tags {
+			counts[tag]++
 		}
 	}
-	if len(eventsByWeek) == 0 {
-		return nil, fmt.Errorf("event: have not events by week since %s", since)
+
+	usage := make([]core.TagUsage, 0, len(counts))
+	for tag, count := range counts {
+		usage = append(usage, core.TagUsage{Tag: tag, Count: count})
 	}
 
-	return eventsByWeek, nil
-}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Count != usage[j].Count {
+			return usage[i].Count > usage[j].Count
+		}
 
-func (es *EventsService) foundEvent(id, userID string) int {
-	eventKey := -1
+		return usage[i].Tag < usage[j].Tag
+	})
 
-	for i, ev := range Events {
-		if ev.ID == id && ev.UserID == userID {
-			eventKey = i
+	return usage, nil
+}
+
+// eventsInRange - события пользователя тенанта в полуинтервале [from, to), подходящие под фильтр, без событий - пустой список, а не ошибка
+func (es *EventsService) eventsInRange(tenantID string, from, to time.Time, userID string, filter core.EventFilter) []core.Event {
+	return es.repo.Events(tenantID, func(ev core.Event) bool {
+		if ev.UserID != userID || ev.Date.Before(from) || !ev.Date.Before(to) {
+			return false
 		}
-	}
 
-	return eventKey
+		return filter.Match(ev)
+	})
 }
 
-func (es *EventsService) existUser(userID string) bool {
-	for _, user := range Users {
-		if user.ID == userID {
-			return true
-		}
+func newEventID() (string, error) {
+	b := make([]byte, 8)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("event: can not generate id: %w", err)
 	}
 
-	return false
+	return hex.EncodeToString(b), nil
 }
 
 func (es *EventsService) validateEvent(event core.Event) error {
@@ -153,6 +187,43 @@ func (es *EventsService) validateEvent(event core.Event) error {
 	if event.Date.IsZero() {
 		return fmt.Errorf("validate: date is empty")
 	}
+	if len(event.Tags) > maxTags {
+		return fmt.Errorf("validate: too many tags, max %d", maxTags)
+	}
+
+	seen := make(map[string]bool, len(event.Tags))
+	for _, tag := range event.Tags {
+		if !tagRe.MatchString(tag) {
+			return fmt.Errorf("validate: bad tag %q, allowed letters, digits, '-' and '_' up to %d symbols", tag, maxTagLen)
+		}
+		if seen[tag] {
+			return fmt.Errorf("validate: duplicate tag %q", tag)
+		}
+		seen[tag] = true
+	}
+
+	if utf8.RuneCountInString(event.Category) > maxCategoryLen {
+		return fmt.Errorf("validate: category is longer than %d symbols", maxCategoryLen)
+	}
+	if event.Color != "" && !colorRe.MatchString(event.Color) {
+		return fmt.Errorf("validate: bad color %q, expected #rgb or #rrggbb", event.Color)
+	}
 
 	return nil
 }
+
+// normalizeEvent - приводит теги к нижнему регистру и обрезает пробелы у тегов, категории и цвета
+func normalizeEvent(event core.Event) core.Event {
+	if len(event.Tags) > 0 {
+		tags := make([]string, len(event.Tags))
+		for i, tag := range event.Tags {
+			tags[i] = strings.ToLower(strings.TrimSpace(tag))
+		}
+		event.Tags = tags
+	}
+
+	event.Category = strings.TrimSpace(event.Category)
+	event.Color = strings.ToLower(strings.TrimSpace(event.Color))
+
+	return event
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"dev11/core"
+	"dev11/repository"
+	"testing"
+	"time"
+)
+
+func newTestStorage(t *testing.T, tenantIDs ...string) *repository.Storage {
+	t.Helper()
+
+	storage := repository.NewStorage()
+	for _, id := range tenantIDs {
+		if err := storage.CreateTenant(core.Tenant{ID: id}, []core.User{{ID: "3"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return storage
+}
+
+func TestValidateEvent(t *testing.T) {
+	day := time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC)
+	es := NewEventsService(newTestStorage(t))
+
+	tests := []struct {
+		name    string
+		event   core.Event
+		wantErr bool
+	}{
+		{
+			name:  "event with tags, category and color",
+			event: core.Event{Text: "standup", Date: day, UserID: "3", Tags: []string{"work", "команда"}, Category: "job", Color: "#ff8800"},
+		},
+		{
+			name:  "short color",
+			event: core.Event{Text: "standup", Date: day, UserID: "3", Color: "#f80"},
+		},
+		{
+			name:    "tag with space",
+			event:   core.Event{Text: "standup", Date: day, UserID: "3", Tags: []string{"day off"}},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate tags",
+			event:   core.Event{Text: "standup", Date: day, UserID: "3", Tags: []string{"work", "work"}},
+			wantErr: true,
+		},
+		{
+			name:    "too many tags",
+			event:   core.Event{Text: "standup", Date: day, UserID: "3", Tags: []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"}},
+			wantErr: true,
+		},
+		{
+			name:    "color is not hex",
+			event:   core.Event{Text: "standup", Date: day, UserID: "3", Color: "red"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := es.validateEvent(normalizeEvent(tt.event))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEventsFilterAndTags(t *testing.T) {
+	es := NewEventsService(newTestStorage(t, core.DefaultTenantID))
+	tenant := core.DefaultTenantID
+	since := time.Date(2024, time.January, 29, 0, 0, 0, 0, time.UTC)
+
+	events := []core.Event{
+		{Text: "standup", Date: since, UserID: "3", Tags: []string{"Work", "team"}, Category: "job"},
+		{Text: "review", Date: since.AddDate(0, 0, 6), UserID: "3", Tags: []string{"work"}, Category: "job"},
+		{Text: "gym", Date: since.AddDate(0, 0, 3), UserID: "3", Tags: []string{"health"}, Category: "home"},
+		{Text: "next week", Date: since.AddDate(0, 0, 7), UserID: "3", Tags: []string{"work"}},
+	}
+	for _, ev := range events {
+		if _, err := es.Create(tenant, ev); err != nil {
+			t.Fatalf("create %s: %s", ev.Text, err)
+		}
+	}
+
+	week, err := es.EventByWeek(tenant, since, "3", core.EventFilter{Tags: []string{"WORK"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(week) != 2 || week[0].Text != "standup" || week[1].Text != "review" {
+		t.Errorf("week by tag = %+v", week)
+	}
+
+	month, err := es.EventByMonth(tenant, since, "3", core.EventFilter{Category: "home"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(month) != 1 || month[0].Text != "gym" {
+		t.Errorf("month by category = %+v", month)
+	}
+
+	// фильтр без подходящих событий даёт пустой список, а не ошибку
+	empty, err := es.EventByDay(tenant, since, "3", core.EventFilter{Tags: []string{"missing"}})
+	if err != nil || empty == nil || len(empty) != 0 {
+		t.Errorf("day by missing tag = %#v, %v", empty, err)
+	}
+
+	usage, err := es.TagsUsage(tenant, "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []core.TagUsage{{Tag: "work", Count: 3}, {Tag: "health", Count: 1}, {Tag: "team", Count: 1}}
+	if len(usage) != len(want) {
+		t.Fatalf("tags = %+v, want %+v", usage, want)
+	}
+	for i := range want {
+		if usage[i] != want[i] {
+			t.Errorf("tags[%d] = %+v, want %+v", i, usage[i], want[i])
+		}
+	}
+}
+
+func TestTenantIsolation(t *testing.T) {
+	es := NewEventsService(newTestStorage(t, "sales", "support"))
+	day := time.Date(2024, time.May, 2, 0, 0, 0, 0, time.UTC)
+
+	created, err := es.Create("sales", core.Event{Text: "deal", Date: day, UserID: "3", Tags: []string{"secret"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if events, err := es.EventByMonth("support", day.AddDate(0, 0, -1), "3", core.EventFilter{}); err != nil || len(events) != 0 {
+		t.Errorf("support sees events of sales: %+v, %v", events, err)
+	}
+
+	if usage, _ := es.TagsUsage("support", "3"); len(usage) != 0 {
+		t.Errorf("support sees tags of sales: %+v", usage)
+	}
+
+	if err := es.Delete("support", created.ID, "3"); err == nil {
+		t.Error("support deleted event of sales")
+	}
+
+	created.Text = "stolen"
+	if err := es.Update("support", created); err == nil {
+		t.Error("support updated event of sales")
+	}
+
+	if _, err := es.Create("unknown", core.Event{Text: "x", Date: day, UserID: "3"}); err == nil {
+		t.Error("event created in unknown tenant")
+	}
+
+	events, err := es.EventByMonth("sales", day, "3", core.EventFilter{})
+	if err != nil || len(events) != 1 || events[0].Text != "deal" {
+		t.Errorf("sales events = %+v, %v", events, err)
+	}
+}
+
+func TestTenantQuotaAndResolve(t *testing.T) {
+	storage := newTestStorage(t, core.DefaultTenantID)
+	ts := NewTenantsService(storage, "admin-secret", false, core.Quota{MaxEvents: 1})
+	headerTS := NewTenantsService(storage, "", true, core.Quota{})
+
+	sales, err := ts.Create(core.Tenant{ID: "sales"}, []core.User{{ID: "3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sales.Token == "" || sales.Quota.MaxEvents != 1 {
+		t.Fatalf("created tenant = %+v", sales)
+	}
+
+	es := NewEventsService(storage)
+	day := time.Date(2024, time.May, 2, 0, 0, 0, 0, time.UTC)
+	if _, err := es.Create("sales", core.Event{Text: "one", Date: day, UserID: "3"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := es.Create("sales", core.Event{Text: "two", Date: day, UserID: "3"}); err == nil {
+		t.Error("events quota is not applied")
+	}
+
+	if _, err := ts.SetQuota("sales", core.Quota{MaxEvents: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := es.Create("sales", core.Event{Text: "two", Date: day, UserID: "3"}); err != nil {
+		t.Errorf("events quota is not updated: %s", err)
+	}
+
+	tests := []struct {
+		name     string
+		ts       *TenantsService
+		token    string
+		header   string
+		wantID   string
+		wantFail bool
+	}{
+		{name: "no token and header is default tenant", wantID: core.DefaultTenantID},
+		{name: "token", token: sales.Token, wantID: "sales"},
+		{name: "token and matching header", token: sales.Token, header: "sales", wantID: "sales"},
+		{name: "token of other tenant", token: sales.Token, header: core.DefaultTenantID, wantFail: true},
+		{name: "bad token", token: "nope", wantFail: true},
+		{name: "header is not allowed", header: "sales", wantFail: true},
+		{name: "allowed header of tenant without token", ts: headerTS, header: core.DefaultTenantID, wantID: core.DefaultTenantID},
+		{name: "allowed header of tenant with token", ts: headerTS, header: "sales", wantFail: true},
+		{name: "allowed header and token", ts: headerTS, token: sales.Token, header: "sales", wantID: "sales"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := ts
+			if tt.ts != nil {
+				resolver = tt.ts
+			}
+
+			tenant, err := resolver.Resolve(tt.token, tt.header)
+			if (err != nil) != tt.wantFail || tenant.ID != tt.wantID {
+				t.Errorf("Resolve() = %q, %v", tenant.ID, err)
+			}
+		})
+	}
+
+	if !ts.IsAdmin("admin-secret") || ts.IsAdmin("") || ts.IsAdmin(sales.Token) {
+		t.Error("IsAdmin() checks token wrong")
+	}
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"dev11/core"
+	"dev11/holiday"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+)
+
+var tenantIDRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+type TenantsRepo interface {
+	CreateTenant(tenant core.Tenant, users []core.User) error
+	Tenant(id string) (core.Tenant, error)
+	TenantByToken(token string) (core.Tenant, error)
+	Tenants() []core.Tenant
+	SetQuota(id string, quota core.Quota) (core.Tenant, error)
+}
+
+/*
+TenantsService - тенанты и определение тенанта запроса
+adminToken string - токен администратора, пустой токен отключает админские методы
+allowHeader bool - можно ли выбирать заголовком без токена тенанта, у которого нет своего токена
+defaultQuota core.Quota - квота для тенантов, созданных без явной квоты
+*/
+type TenantsService struct {
+	repo         TenantsRepo
+	adminToken   string
+	allowHeader  bool
+	defaultQuota core.Quota
+}
+
+func NewTenantsService(repo TenantsRepo, adminToken string, allowHeader bool, defaultQuota core.Quota) *TenantsService {
+	return &TenantsService{
+		repo:         repo,
+		adminToken:   adminToken,
+		allowHeader:  allowHeader,
+		defaultQuota: defaultQuota,
+	}
+}
+
+// Create - создаёт тенанта с пользователями и выдаёт ему токен
+func (ts *TenantsService) Create(tenant core.Tenant, users []core.User) (core.Tenant, error) {
+	if !tenantIDRe.MatchString(tenant.ID) {
+		return core.Tenant{}, fmt.Errorf("tenant: bad id %q, allowed lowercase letters, digits, '-' and '_'", tenant.ID)
+	}
+
+	if err := validateQuota(tenant.Quota); err != nil {
+		return core.Tenant{}, err
+	}
+
+	if _, err := holiday.Load(tenant.HolidayCountries, nil); err != nil {
+		return core.Tenant{}, fmt.Errorf("tenant: %w", err)
+	}
+
+	for _, u := range users {
+		if u.ID == "" {
+			return core.Tenant{}, fmt.Errorf("tenant: user without id")
+		}
+	}
+
+	if tenant.Quota == (core.Quota{}) {
+		tenant.Quota = ts.defaultQuota
+	}
+
+	token, err := newToken()
+
+	if err != nil {
+		return core.Tenant{}, err
+	}
+
+	tenant.Token = token
+
+	if err := ts.repo.CreateTenant(tenant, users); err != nil {
+		return core.Tenant{}, fmt.Errorf("tenant: %w", err)
+	}
+
+	return tenant, nil
+}
+
+func (ts *TenantsService) SetQuota(id string, quota core.Quota) (core.Tenant, error) {
+	if err := validateQuota(quota); err != nil {
+		return core.Tenant{}, err
+	}
+
+	tenant, err := ts.repo.SetQuota(id, quota)
+
+	if err != nil {
+		return core.Tenant{}, fmt.Errorf("tenant: %w", err)
+	}
+
+	tenant.Token = ""
+
+	return tenant, nil
+}
+
+// Tenants - все тенанты без токенов, отсортированные по id
+func (ts *TenantsService) Tenants() []core.Tenant {
+	tenants := ts.repo.Tenants()
+
+	for i := range tenants {
+		tenants[i].Token = ""
+	}
+
+	sort.Slice(tenants, func(i, j int) bool {
+		return tenants[i].ID < tenants[j].ID
+	})
+
+	return tenants
+}
+
+/*
+Resolve - определяет тенанта запроса
+если передан токен, тенант берётся по нему, а заголовок должен совпадать с ним или отсутствовать
+без токена тенант берётся из заголовка (если это разрешено), иначе используется тенант по умолчанию,
+заголовком выбирается только тенант без токена, иначе любой клиент мог бы работать с чужими событиями, назвав тенанта
+*/
+func (ts *TenantsService) Resolve(token, tenantID string) (core.Tenant, error) {
+	if token != "" {
+		tenant, err := ts.repo.TenantByToken(token)
+
+		if err != nil {
+			return core.Tenant{}, fmt.Errorf("tenant: %w: bad token", ErrUnauthorized)
+		}
+
+		if tenantID != "" && tenantID != tenant.ID {
+			return core.Tenant{}, fmt.Errorf("tenant: %w: token does not belong to tenant %s", ErrForbidden, tenantID)
+		}
+
+		return tenant, nil
+	}
+
+	if tenantID == "" {
+		tenantID = core.DefaultTenantID
+	} else if !ts.allowHeader {
+		return core.Tenant{}, fmt.Errorf("tenant: %w: tenant header is not allowed, use token", ErrUnauthorized)
+	}
+
+	tenant, err := ts.repo.Tenant(tenantID)
+
+	if err != nil {
+		return core.Tenant{}, fmt.Errorf("tenant: %w: unknown tenant %s", ErrUnauthorized, tenantID)
+	}
+
+	if tenant.Token != "" {
+		return core.Tenant{}, fmt.Errorf("tenant: %w: tenant %s requires token", ErrUnauthorized, tenantID)
+	}
+
+	return tenant, nil
+}
+
+func (ts *TenantsService) IsAdmin(token string) bool {
+	if ts.adminToken == "" || token == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(ts.adminToken), []byte(token)) == 1
+}
+
+func validateQuota(quota core.Quota) error {
+	if quota.RequestsPerMinute < 0 || quota.MaxEvents < 0 {
+		return fmt.Errorf("tenant: quota can not be negative")
+	}
+
+	return nil
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 24)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("tenant: can not generate token: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
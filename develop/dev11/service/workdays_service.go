@@ -0,0 +1,108 @@
+package service
+
+import (
+	"dev11/core"
+	"dev11/holiday"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxWorkdaysRange = 10 * 366
+	maxWorkdaysAdd   = 10 * 260
+)
+
+type TenantLookup interface {
+	Tenant(id string) (core.Tenant, error)
+}
+
+/*
+WorkdaysService - рабочие дни по производственному календарю
+cal *holiday.Calendar - календарь из конфига сервера, используется тенантами без своих стран
+calendars map[string]*holiday.Calendar - календари тенантов со своими странами, ключ - список стран
+*/
+type WorkdaysService struct {
+	cal       *holiday.Calendar
+	tenants   TenantLookup
+	mu        sync.Mutex
+	calendars map[string]*holiday.Calendar
+}
+
+func NewWorkdaysService(cal *holiday.Calendar, tenants TenantLookup) *WorkdaysService {
+	return &WorkdaysService{
+		cal:       cal,
+		tenants:   tenants,
+		calendars: make(map[string]*holiday.Calendar),
+	}
+}
+
+func (ws *WorkdaysService) Count(tenantID string, from, to time.Time) (core.WorkdaysCount, error) {
+	if days := to.Sub(from).Hours() / 24; days > maxWorkdaysRange || days < -maxWorkdaysRange {
+		return core.WorkdaysCount{}, fmt.Errorf("workdays: range is longer than %d days", maxWorkdaysRange)
+	}
+
+	return core.WorkdaysCount{
+		From:     from,
+		To:       to,
+		Workdays: ws.calendar(tenantID).WorkdaysBetween(from, to),
+	}, nil
+}
+
+func (ws *WorkdaysService) Add(tenantID string, from time.Time, n int) (core.WorkdaysAdd, error) {
+	if n > maxWorkdaysAdd || n < -maxWorkdaysAdd {
+		return core.WorkdaysAdd{}, fmt.Errorf("workdays: can not add more than %d workdays", maxWorkdaysAdd)
+	}
+
+	return core.WorkdaysAdd{
+		From: from,
+		Add:  n,
+		Date: ws.calendar(tenantID).AddWorkdays(from, n),
+	}, nil
+}
+
+// HolidayWarnings - предупреждения для даты события, если она выпадает на праздник или выходной
+func (ws *WorkdaysService) HolidayWarnings(tenantID string, date time.Time) []string {
+	cal := ws.calendar(tenantID)
+
+	if h, ok := cal.HolidayOn(date); ok {
+		return []string{fmt.Sprintf("%s is a holiday: %s", date.Format("2006-01-02"), h.Name)}
+	}
+
+	if !cal.IsWorkday(date) {
+		return []string{fmt.Sprintf("%s is a day off", date.Format("2006-01-02"))}
+	}
+
+	return nil
+}
+
+// calendar - календарь тенанта: по его странам, если они заданы, иначе календарь сервера
+func (ws *WorkdaysService) calendar(tenantID string) *holiday.Calendar {
+	tenant, err := ws.tenants.Tenant(tenantID)
+
+	if err != nil || len(tenant.HolidayCountries) == 0 {
+		return ws.cal
+	}
+
+	key := strings.ToUpper(strings.Join(tenant.HolidayCountries, ","))
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if cal, ok := ws.calendars[key]; ok {
+		return cal
+	}
+
+	cal, err := holiday.Load(tenant.HolidayCountries, nil)
+
+	if err != nil {
+		log.Printf("workdays: can not load holidays of tenant %s: %s", tenantID, err)
+		return ws.cal
+	}
+
+	ws.calendars[key] = cal
+
+	return cal
+}
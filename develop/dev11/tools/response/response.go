@@ -7,6 +7,8 @@ import (
 	"net/http"
 )
 
+// Resp - пишет в ответ res или errResp в виде JSON,
+// значения можно передавать как есть или уже обёрнутыми в core.SuccessResponse / core.ErrorResponse
 func Resp(w http.ResponseWriter, res interface{}, errResp interface{}, status ...int) {
 	var st int
 	for _, s := range status {
@@ -17,40 +19,39 @@ func Resp(w http.ResponseWriter, res interface{}, errResp interface{}, status ..
 	if st < 100 {
 		st = 200
 	}
-	var respSuccess core.SuccessResponse
-	var respError core.ErrorResponse
+
+	var body interface{}
 	if errResp == nil {
-		respSuccess = core.SuccessResponse{
-			Result: res,
+		success, ok := res.(core.SuccessResponse)
+		if !ok {
+			success = core.SuccessResponse{
+				Result: res,
+			}
 		}
+		body = success
 	} else {
-		respError = core.ErrorResponse{
+		if wrapped, ok := errResp.(core.ErrorResponse); ok {
+			errResp = wrapped.Error
+		}
+		if err, ok := errResp.(error); ok {
+			errResp = err.Error()
+		}
+		body = core.ErrorResponse{
 			Error: errResp,
 		}
 	}
 
-	if respSuccess.Result != nil {
-		respSucc, err := json.Marshal(respSuccess)
+	data, err := json.Marshal(body)
 
-		if err != nil {
-			log.Printf("response: can not marshal response: %s\n", err.Error())
-			return
-		}
-		w.WriteHeader(st)
-		if _, err := w.Write(respSucc); err != nil {
-			log.Printf("response: can not write response: %s\n", err.Error())
-		}
+	if err != nil {
+		log.Printf("response: can not marshal response: %s\n", err.Error())
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 
-	if respError.Error != nil {
-		respErr, err := json.Marshal(respSuccess)
-
-		if err != nil {
-			log.Printf("response: can not marshal response: %s\n", err.Error())
-			return
-		}
-
-		http.Error(w, string(respErr), st)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(st)
+	if _, err := w.Write(data); err != nil {
+		log.Printf("response: can not write response: %s\n", err.Error())
 	}
 }
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"dev11/docs"
+	"dev11/tools/response"
+	"log"
+	"net/http"
+)
+
+func (hh *HTTPHandler) openAPI(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, err := w.Write(docs.Spec()); err != nil {
+		log.Printf("openapi: can not write response: %s\n", err.Error())
+	}
+}
+
+func (hh *HTTPHandler) docs(w http.ResponseWriter, req *http.Request) {
+	page, err := docs.Page()
+
+	if err != nil {
+		response.Resp(w, nil, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if _, err := w.Write(page); err != nil {
+		log.Printf("docs: can not write response: %s\n", err.Error())
+	}
+}
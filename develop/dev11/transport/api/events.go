@@ -2,20 +2,19 @@ package api
 
 import (
 	"dev11/core"
+	"dev11/middleware"
 	"dev11/tools/response"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
 func (hh *HTTPHandler) createEvent(w http.ResponseWriter, req *http.Request) {
 	nameMethod := "create evet"
-	if req.Method != http.MethodPost {
-		response.Resp(w, nil, nil, http.StatusNotFound)
-	}
-
 	var newEvent core.Event
 	body := req.Body
 	defer func() {
@@ -35,24 +34,27 @@ func (hh *HTTPHandler) createEvent(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	errC := hh.sv.Create(newEvent)
+	created, errC := hh.sv.Create(middleware.TenantID(req.Context()), newEvent)
 
 	if errC != nil {
 		response.Resp(w, nil, errC, http.StatusBadRequest)
 		return
 	}
 
-	resp := core.SuccessResponse{Result: "ok"}
-	log.Printf("%s: event %s is created\n", nameMethod, newEvent)
+	resp := core.SuccessResponse{Result: created}
+
+	// при warn_holidays=true предупреждаем, что событие попало на праздник или выходной
+	if warn, _ := strconv.ParseBool(req.URL.Query().Get("warn_holidays")); warn {
+		resp.Warnings = hh.wd.HolidayWarnings(middleware.TenantID(req.Context()), created.Date)
+	}
+
+	log.Printf("%s: event %s is created\n", nameMethod, created.ID)
 
 	response.Resp(w, resp, nil, http.StatusCreated)
 }
 
 func (hh *HTTPHandler) updateEvent(w http.ResponseWriter, req *http.Request) {
 	nameMethod := "update event"
-	if req.Method != http.MethodPut {
-		response.Resp(w, nil, nil, http.StatusNotFound)
-	}
 	var newEvent core.Event
 	body := req.Body
 	defer func() {
@@ -75,7 +77,7 @@ func (hh *HTTPHandler) updateEvent(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	errC := hh.sv.Update(newEvent)
+	errC := hh.sv.Update(middleware.TenantID(req.Context()), newEvent)
 
 	if errC != nil {
 		errResp := core.ErrorResponse{
@@ -86,17 +88,13 @@ func (hh *HTTPHandler) updateEvent(w http.ResponseWriter, req *http.Request) {
 	}
 
 	resp := core.SuccessResponse{Result: "ok"}
-	log.Printf("%s: event %s is created\n", nameMethod, newEvent)
+	log.Printf("%s: event %s is updated\n", nameMethod, newEvent.ID)
 
-	response.Resp(w, resp, nil, http.StatusCreated)
+	response.Resp(w, resp, nil, http.StatusOK)
 }
 
 func (hh *HTTPHandler) deleteEvent(w http.ResponseWriter, req *http.Request) {
 	nameMethod := "delete event"
-	if req.Method != http.MethodDelete {
-		response.Resp(w, nil, nil, http.StatusNotFound)
-	}
-
 	var delEvent struct {
 		ID     string `json:"id"`
 		UserID string `json:"user_id"`
@@ -120,7 +118,7 @@ func (hh *HTTPHandler) deleteEvent(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	errDel := hh.sv.Delete(delEvent.ID, delEvent.UserID)
+	errDel := hh.sv.Delete(middleware.TenantID(req.Context()), delEvent.ID, delEvent.UserID)
 
 	if errDel != nil {
 		errResp := core.ErrorResponse{
@@ -130,18 +128,14 @@ func (hh *HTTPHandler) deleteEvent(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	log.Printf("%s: evetn by id %s is deleted", nameMethod, http.StatusOK)
+	log.Printf("%s: event by id %s is deleted", nameMethod, delEvent.ID)
 	resp := core.SuccessResponse{Result: "ok"}
 
-	response.Resp(w, resp, nil, http.StatusCreated)
+	response.Resp(w, resp, nil, http.StatusOK)
 }
 
 func (hh *HTTPHandler) eventsForDay(w http.ResponseWriter, req *http.Request) {
 	nameMethod := "events for day"
-	if req.Method != http.MethodGet {
-		response.Resp(w, nil, nil, http.StatusNotFound)
-	}
-
 	day := req.URL.Query().Get("day")
 	userID := req.URL.Query().Get("user_id")
 
@@ -186,11 +180,11 @@ func (hh *HTTPHandler) eventsForDay(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	events, errEBD := hh.sv.EventByDay(dayTime, userID)
+	events, errEBD := hh.sv.EventByDay(middleware.TenantID(req.Context()), dayTime, userID, eventFilter(req))
 
 	if errEBD != nil {
 		errResp := core.ErrorResponse{
-			Error: fmt.Errorf("%s: bad event: bad day data format: %s\n", nameMethod, errP),
+			Error: fmt.Errorf("%s: %s", nameMethod, errEBD),
 		}
 		response.Resp(w,
 			nil,
@@ -207,15 +201,11 @@ func (hh *HTTPHandler) eventsForDay(w http.ResponseWriter, req *http.Request) {
 		Result: events,
 	}
 
-	response.Resp(w, resp, nil, http.StatusCreated)
+	response.Resp(w, resp, nil, http.StatusOK)
 }
 
 func (hh *HTTPHandler) eventsForWeek(w http.ResponseWriter, req *http.Request) {
 	nameMethod := "events for week"
-	if req.Method != http.MethodGet {
-		response.Resp(w, nil, nil, http.StatusNotFound)
-	}
-
 	since := req.URL.Query().Get("since")
 	userID := req.URL.Query().Get("user_id")
 
@@ -260,11 +250,11 @@ func (hh *HTTPHandler) eventsForWeek(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	events, errEBD := hh.sv.EventByWeek(sinceTime, userID)
+	events, errEBD := hh.sv.EventByWeek(middleware.TenantID(req.Context()), sinceTime, userID, eventFilter(req))
 
 	if errEBD != nil {
 		errResp := core.ErrorResponse{
-			Error: fmt.Errorf("%s: bad event: bad since data format: %s\n", nameMethod, errP),
+			Error: fmt.Errorf("%s: %s", nameMethod, errEBD),
 		}
 		response.Resp(w,
 			nil,
@@ -281,15 +271,11 @@ func (hh *HTTPHandler) eventsForWeek(w http.ResponseWriter, req *http.Request) {
 		Result: events,
 	}
 
-	response.Resp(w, resp, nil, http.StatusCreated)
+	response.Resp(w, resp, nil, http.StatusOK)
 }
 
 func (hh *HTTPHandler) eventsForMonth(w http.ResponseWriter, req *http.Request) {
 	nameMethod := "events for month"
-	if req.Method != http.MethodGet {
-		response.Resp(w, nil, nil, http.StatusNotFound)
-	}
-
 	since := req.URL.Query().Get("since")
 	userID := req.URL.Query().Get("user_id")
 
@@ -334,11 +320,11 @@ func (hh *HTTPHandler) eventsForMonth(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	events, errEBD := hh.sv.EventByMonth(sinceTime, userID)
+	events, errEBD := hh.sv.EventByMonth(middleware.TenantID(req.Context()), sinceTime, userID, eventFilter(req))
 
 	if errEBD != nil {
 		errResp := core.ErrorResponse{
-			Error: fmt.Errorf("%s: bad event: bad since data format: %s\n", nameMethod, errP),
+			Error: fmt.Errorf("%s: %s", nameMethod, errEBD),
 		}
 		response.Resp(w,
 			nil,
@@ -355,5 +341,23 @@ func (hh *HTTPHandler) eventsForMonth(w http.ResponseWriter, req *http.Request)
 		Result: events,
 	}
 
-	response.Resp(w, resp, nil, http.StatusCreated)
+	response.Resp(w, resp, nil, http.StatusOK)
+}
+
+// eventFilter - фильтр событий из параметров запроса: tag= (можно повторять или перечислять через запятую) и category=
+func eventFilter(req *http.Request) core.EventFilter {
+	query := req.URL.Query()
+	filter := core.EventFilter{
+		Category: strings.TrimSpace(query.Get("category")),
+	}
+
+	for _, param := range query["tag"] {
+		for _, tag := range strings.Split(param, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				filter.Tags = append(filter.Tags, tag)
+			}
+		}
+	}
+
+	return filter
 }
@@ -3,42 +3,112 @@ package api
 import (
 	"dev11/core"
 	"dev11/middleware"
+	"dev11/tools/response"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 )
 
 type HTTPHandler struct {
-	mux *http.ServeMux
-	sv  EventServ
+	mux     *http.ServeMux
+	sv      EventServ
+	wd      WorkdaysServ
+	tn      TenantServ
+	limiter *middleware.RateLimiter
+	routes  []Route
 }
 
-func NewHTTPHandler(sv EventServ) *HTTPHandler {
+// Route - зарегистрированный путь и методы, которые он принимает
+type Route struct {
+	Path    string
+	Methods []string
+}
+
+func NewHTTPHandler(sv EventServ, wd WorkdaysServ, tn TenantServ) *HTTPHandler {
 	return &HTTPHandler{
-		mux: http.NewServeMux(),
-		sv:  sv,
+		mux:     http.NewServeMux(),
+		sv:      sv,
+		wd:      wd,
+		tn:      tn,
+		limiter: middleware.NewRateLimiter(),
 	}
 }
 
 func (hh *HTTPHandler) Handler() http.Handler {
-	hh.handle("/create_event/", hh.createEvent)
-	hh.handle("/update_event/", hh.updateEvent)
-	hh.handle("/delete_event/", hh.deleteEvent)
-	hh.handle("/events_for_day/", hh.eventsForDay)
-	hh.handle("/events_for_week/", hh.eventsForWeek)
-	hh.handle("/events_for_month/", hh.eventsForMonth)
+	hh.handle("/create_event/", hh.createEvent, http.MethodPost)
+	hh.handle("/update_event/", hh.updateEvent, http.MethodPut)
+	hh.handle("/delete_event/", hh.deleteEvent, http.MethodDelete)
+	hh.handle("/events_for_day/", hh.eventsForDay, http.MethodGet)
+	hh.handle("/events_for_week/", hh.eventsForWeek, http.MethodGet)
+	hh.handle("/events_for_month/", hh.eventsForMonth, http.MethodGet)
+	hh.handle("/tags", hh.tags, http.MethodGet)
+	hh.handle("/workdays", hh.workdays, http.MethodGet)
+	hh.handleAdmin("/admin/tenants", hh.adminTenants, http.MethodGet, http.MethodPost)
+	hh.handleAdmin("/admin/tenants/quota", hh.adminTenantQuota, http.MethodPut)
+	hh.handlePublic("/openapi.json", hh.openAPI, http.MethodGet)
+	hh.handlePublic("/docs", hh.docs, http.MethodGet)
 
 	return hh.mux
 }
 
-func (hh *HTTPHandler) handle(path string, hf http.HandlerFunc) {
-	hh.mux.Handle(path, middleware.Middleware(hf))
+// Routes - все зарегистрированные пути, заполняется при вызове Handler
+func (hh *HTTPHandler) Routes() []Route {
+	return hh.routes
+}
+
+func (hh *HTTPHandler) handle(path string, hf http.HandlerFunc, methods ...string) {
+	hh.register(path, hh.withTenant(hf), methods)
+}
+
+func (hh *HTTPHandler) handleAdmin(path string, hf http.HandlerFunc, methods ...string) {
+	hh.register(path, hh.withAdmin(hf), methods)
+}
+
+func (hh *HTTPHandler) handlePublic(path string, hf http.HandlerFunc, methods ...string) {
+	hh.register(path, hf, methods)
+}
+
+func (hh *HTTPHandler) register(path string, hf http.HandlerFunc, methods []string) {
+	hh.routes = append(hh.routes, Route{Path: path, Methods: methods})
+	hh.mux.Handle(path, middleware.Middleware(allowMethods(hf, methods)))
+}
+
+// allowMethods - отвечает 405, если метод запроса не из списка
+func allowMethods(next http.HandlerFunc, methods []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		for _, m := range methods {
+			if req.Method == m {
+				next(w, req)
+				return
+			}
+		}
+
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		response.Resp(w, nil, fmt.Errorf("method %s not allowed", req.Method), http.StatusMethodNotAllowed)
+	}
 }
 
 type EventServ interface {
-	Create(event core.Event) error
-	Update(event core.Event) error
-	Delete(evID, userID string) error
-	EventByDay(day time.Time, userID string) ([]core.Event, error)
-	EventByWeek(since time.Time, userID string) ([]core.Event, error)
-	EventByMonth(since time.Time, userID string) ([]core.Event, error)
+	Create(tenantID string, event core.Event) (core.Event, error)
+	Update(tenantID string, event core.Event) error
+	Delete(tenantID, evID, userID string) error
+	EventByDay(tenantID string, day time.Time, userID string, filter core.EventFilter) ([]core.Event, error)
+	EventByWeek(tenantID string, since time.Time, userID string, filter core.EventFilter) ([]core.Event, error)
+	EventByMonth(tenantID string, since time.Time, userID string, filter core.EventFilter) ([]core.Event, error)
+	TagsUsage(tenantID, userID string) ([]core.TagUsage, error)
+}
+
+type WorkdaysServ interface {
+	Count(tenantID string, from, to time.Time) (core.WorkdaysCount, error)
+	Add(tenantID string, from time.Time, n int) (core.WorkdaysAdd, error)
+	HolidayWarnings(tenantID string, date time.Time) []string
+}
+
+type TenantServ interface {
+	Resolve(token, tenantID string) (core.Tenant, error)
+	IsAdmin(token string) bool
+	Create(tenant core.Tenant, users []core.User) (core.Tenant, error)
+	SetQuota(id string, quota core.Quota) (core.Tenant, error)
+	Tenants() []core.Tenant
 }
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"dev11/core"
+	"dev11/docs"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type openAPIDoc struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas   map[string]json.RawMessage `json:"schemas"`
+		Responses map[string]json.RawMessage `json:"responses"`
+	} `json:"components"`
+}
+
+func loadSpec(t *testing.T) openAPIDoc {
+	t.Helper()
+
+	var doc openAPIDoc
+	if err := json.Unmarshal(docs.Spec(), &doc); err != nil {
+		t.Fatalf("bad openapi.json: %s", err)
+	}
+
+	return doc
+}
+
+func TestOpenAPIRoutes(t *testing.T) {
+	doc := loadSpec(t)
+
+	hh := NewHTTPHandler(nil, nil, nil)
+	hh.Handler()
+
+	registered := make([]string, 0)
+	for _, r := range hh.Routes() {
+		for _, m := range r.Methods {
+			registered = append(registered, strings.ToLower(m)+" "+r.Path)
+		}
+	}
+
+	documented := make([]string, 0)
+	for path, ops := range doc.Paths {
+		for m := range ops {
+			documented = append(documented, m+" "+path)
+		}
+	}
+
+	sort.Strings(registered)
+	sort.Strings(documented)
+
+	if !reflect.DeepEqual(registered, documented) {
+		t.Errorf("routes drifted from openapi.json\nregistered: %v\ndocumented: %v", registered, documented)
+	}
+}
+
+func TestOpenAPISchemas(t *testing.T) {
+	doc := loadSpec(t)
+
+	tests := []struct {
+		schema string
+		typ    interface{}
+	}{
+		{schema: "Event", typ: core.Event{}},
+		{schema: "TagUsage", typ: core.TagUsage{}},
+		{schema: "WorkdaysCount", typ: core.WorkdaysCount{}},
+		{schema: "WorkdaysAdd", typ: core.WorkdaysAdd{}},
+		{schema: "Quota", typ: core.Quota{}},
+		{schema: "User", typ: core.User{}},
+		{schema: "Tenant", typ: core.Tenant{}},
+		{schema: "ErrorResponse", typ: core.ErrorResponse{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			raw, ok := doc.Components.Schemas[tt.schema]
+			if !ok {
+				t.Fatalf("schema %s is not documented", tt.schema)
+			}
+
+			var schema struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			}
+			if err := json.Unmarshal(raw, &schema); err != nil {
+				t.Fatal(err)
+			}
+
+			documented := make([]string, 0, len(schema.Properties))
+			for name := range schema.Properties {
+				documented = append(documented, name)
+			}
+
+			fields := jsonFields(reflect.TypeOf(tt.typ))
+
+			sort.Strings(documented)
+			sort.Strings(fields)
+
+			if !reflect.DeepEqual(documented, fields) {
+				t.Errorf("schema %s drifted from Go type\nGo fields: %v\ndocumented: %v", tt.schema, fields, documented)
+			}
+		})
+	}
+}
+
+func TestOpenAPIRefs(t *testing.T) {
+	doc := loadSpec(t)
+
+	var refs []string
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for k, child := range v {
+				if s, ok := child.(string); ok && k == "$ref" {
+					refs = append(refs, s)
+				}
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+
+	var all interface{}
+	if err := json.Unmarshal(docs.Spec(), &all); err != nil {
+		t.Fatal(err)
+	}
+	walk(all)
+
+	for _, ref := range refs {
+		name := ref[strings.LastIndex(ref, "/")+1:]
+
+		switch {
+		case strings.HasPrefix(ref, "#/components/schemas/"):
+			if _, ok := doc.Components.Schemas[name]; !ok {
+				t.Errorf("unresolved ref %s", ref)
+			}
+		case strings.HasPrefix(ref, "#/components/responses/"):
+			if _, ok := doc.Components.Responses[name]; !ok {
+				t.Errorf("unresolved ref %s", ref)
+			}
+		default:
+			t.Errorf("unexpected ref %s", ref)
+		}
+	}
+}
+
+func TestDocsRoutes(t *testing.T) {
+	hh := NewHTTPHandler(nil, nil, nil)
+	srv := httptest.NewServer(hh.Handler())
+	defer srv.Close()
+
+	tests := []struct {
+		path        string
+		method      string
+		wantStatus  int
+		contentType string
+	}{
+		{path: "/openapi.json", method: http.MethodGet, wantStatus: http.StatusOK, contentType: "application/json"},
+		{path: "/docs", method: http.MethodGet, wantStatus: http.StatusOK, contentType: "text/html; charset=utf-8"},
+		{path: "/docs", method: http.MethodPost, wantStatus: http.StatusMethodNotAllowed, contentType: "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, srv.URL+tt.path, nil)
+
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus || resp.Header.Get("Content-Type") != tt.contentType {
+				t.Errorf("got %d %s, want %d %s", resp.StatusCode, resp.Header.Get("Content-Type"), tt.wantStatus, tt.contentType)
+			}
+		})
+	}
+}
+
+// jsonFields - имена полей структуры в JSON, включая поля встроенных структур
+func jsonFields(typ reflect.Type) []string {
+	fields := make([]string, 0, typ.NumField())
+
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			fields = append(fields, jsonFields(f.Type)...)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fields = append(fields, name)
+	}
+
+	return fields
+}
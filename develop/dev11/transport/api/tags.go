@@ -0,0 +1,43 @@
+package api
+
+import (
+	"dev11/core"
+	"dev11/middleware"
+	"dev11/tools/response"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+func (hh *HTTPHandler) tags(w http.ResponseWriter, req *http.Request) {
+	nameMethod := "tags"
+	userID := req.URL.Query().Get("user_id")
+
+	if userID == "" {
+		errResp := core.ErrorResponse{
+			Error: fmt.Errorf("%s: user_id is required", nameMethod),
+		}
+		response.Resp(w, nil, errResp, http.StatusBadRequest)
+
+		return
+	}
+
+	usage, errTU := hh.sv.TagsUsage(middleware.TenantID(req.Context()), userID)
+
+	if errTU != nil {
+		errResp := core.ErrorResponse{
+			Error: fmt.Errorf("%s: %s", nameMethod, errTU),
+		}
+		response.Resp(w, nil, errResp, http.StatusNotFound)
+
+		return
+	}
+
+	log.Printf("%s: return %d tags of user %s", nameMethod, len(usage), userID)
+
+	resp := core.SuccessResponse{
+		Result: usage,
+	}
+
+	response.Resp(w, resp, nil, http.StatusOK)
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"dev11/core"
+	"dev11/middleware"
+	"dev11/service"
+	"dev11/tools/response"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+const tenantHeader = "X-Tenant-ID"
+
+// withTenant - определяет тенанта по токену или заголовку X-Tenant-ID и проверяет его лимит запросов
+func (hh *HTTPHandler) withTenant(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		tenant, err := hh.tn.Resolve(bearerToken(req), req.Header.Get(tenantHeader))
+
+		if err != nil {
+			status := http.StatusUnauthorized
+			if errors.Is(err, service.ErrForbidden) {
+				status = http.StatusForbidden
+			}
+			response.Resp(w, nil, err, status)
+
+			return
+		}
+
+		if !hh.limiter.Allow(tenant.ID, tenant.Quota.RequestsPerMinute) {
+			response.Resp(w, nil, fmt.Errorf("tenant %s: rate limit exceeded", tenant.ID), http.StatusTooManyRequests)
+
+			return
+		}
+
+		next(w, req.WithContext(middleware.WithTenant(req.Context(), tenant.ID)))
+	}
+}
+
+// withAdmin - пропускает только запросы с токеном администратора
+func (hh *HTTPHandler) withAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if !hh.tn.IsAdmin(bearerToken(req)) {
+			response.Resp(w, nil, fmt.Errorf("admin: bad or missing admin token"), http.StatusForbidden)
+
+			return
+		}
+
+		next(w, req)
+	}
+}
+
+func bearerToken(req *http.Request) string {
+	auth := req.Header.Get("Authorization")
+	token, ok := strings.CutPrefix(auth, "Bearer ")
+
+	if !ok {
+		return ""
+	}
+
+	return strings.TrimSpace(token)
+}
+
+/*
+adminTenants - список тенантов (GET) и создание тенанта (POST)
+тело POST: {"id": "sales", "name": "Sales", "quota": {...}, "holiday_countries": ["RU"], "users": [{"id": "1", "user_name": "anna"}]}
+*/
+func (hh *HTTPHandler) adminTenants(w http.ResponseWriter, req *http.Request) {
+	nameMethod := "admin tenants"
+
+	switch req.Method {
+	case http.MethodGet:
+		response.Resp(w, core.SuccessResponse{Result: hh.tn.Tenants()}, nil, http.StatusOK)
+	case http.MethodPost:
+		var newTenant struct {
+			core.Tenant
+			Users []core.User `json:"users"`
+		}
+
+		body := req.Body
+		defer func() {
+			if err := body.Close(); err != nil {
+				log.Printf("%s: can not close body: %s\n", nameMethod, err.Error())
+			}
+		}()
+
+		if errD := json.NewDecoder(body).Decode(&newTenant); errD != nil {
+			response.Resp(w, nil, fmt.Errorf("%s: bad tenant: %s", nameMethod, errD), http.StatusBadRequest)
+			return
+		}
+
+		created, errC := hh.tn.Create(newTenant.Tenant, newTenant.Users)
+
+		if errC != nil {
+			response.Resp(w, nil, errC, http.StatusBadRequest)
+			return
+		}
+
+		log.Printf("%s: tenant %s is created\n", nameMethod, created.ID)
+
+		response.Resp(w, core.SuccessResponse{Result: created}, nil, http.StatusCreated)
+	}
+}
+
+// adminTenantQuota - изменение квоты тенанта, тело: {"id": "sales", "quota": {"requests_per_minute": 60, "max_events": 1000}}
+func (hh *HTTPHandler) adminTenantQuota(w http.ResponseWriter, req *http.Request) {
+	nameMethod := "admin tenant quota"
+	var quota struct {
+		ID    string     `json:"id"`
+		Quota core.Quota `json:"quota"`
+	}
+
+	body := req.Body
+	defer func() {
+		if err := body.Close(); err != nil {
+			log.Printf("%s: can not close body: %s\n", nameMethod, err.Error())
+		}
+	}()
+
+	if errD := json.NewDecoder(body).Decode(&quota); errD != nil {
+		response.Resp(w, nil, fmt.Errorf("%s: bad quota: %s", nameMethod, errD), http.StatusBadRequest)
+		return
+	}
+
+	tenant, errQ := hh.tn.SetQuota(quota.ID, quota.Quota)
+
+	if errQ != nil {
+		response.Resp(w, nil, errQ, http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("%s: quota of tenant %s is updated\n", nameMethod, tenant.ID)
+
+	response.Resp(w, core.SuccessResponse{Result: tenant}, nil, http.StatusOK)
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"dev11/core"
+	"dev11/middleware"
+	"dev11/tools/response"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+/*
+workdays - рабочие дни по производственному календарю
+GET /workdays?from=2024-01-01&to=2024-02-01 - количество рабочих дней в [from, to)
+GET /workdays?from=2024-01-01&add=5 - дата через 5 рабочих дней после from
+*/
+func (hh *HTTPHandler) workdays(w http.ResponseWriter, req *http.Request) {
+	nameMethod := "workdays"
+	query := req.URL.Query()
+	from, to, add := query.Get("from"), query.Get("to"), query.Get("add")
+
+	if from == "" || (to == "") == (add == "") {
+		errResp := core.ErrorResponse{
+			Error: fmt.Errorf("%s: from and exactly one of to or add are required", nameMethod),
+		}
+		response.Resp(w, nil, errResp, http.StatusBadRequest)
+
+		return
+	}
+
+	fromTime, errP := time.Parse("2006-01-02", from)
+
+	if errP != nil {
+		errResp := core.ErrorResponse{
+			Error: fmt.Errorf("%s: bad from data format: %s", nameMethod, errP),
+		}
+		response.Resp(w, nil, errResp, http.StatusBadRequest)
+
+		return
+	}
+
+	var (
+		result interface{}
+		errW   error
+	)
+
+	if to != "" {
+		toTime, errP := time.Parse("2006-01-02", to)
+
+		if errP != nil {
+			errResp := core.ErrorResponse{
+				Error: fmt.Errorf("%s: bad to data format: %s", nameMethod, errP),
+			}
+			response.Resp(w, nil, errResp, http.StatusBadRequest)
+
+			return
+		}
+
+		result, errW = hh.wd.Count(middleware.TenantID(req.Context()), fromTime, toTime)
+	} else {
+		n, errA := strconv.Atoi(add)
+
+		if errA != nil {
+			errResp := core.ErrorResponse{
+				Error: fmt.Errorf("%s: add must be an integer: %s", nameMethod, add),
+			}
+			response.Resp(w, nil, errResp, http.StatusBadRequest)
+
+			return
+		}
+
+		result, errW = hh.wd.Add(middleware.TenantID(req.Context()), fromTime, n)
+	}
+
+	if errW != nil {
+		errResp := core.ErrorResponse{
+			Error: errW,
+		}
+		response.Resp(w, nil, errResp, http.StatusBadRequest)
+
+		return
+	}
+
+	log.Printf("%s: return workdays since %s", nameMethod, fromTime)
+
+	resp := core.SuccessResponse{
+		Result: result,
+	}
+
+	response.Resp(w, resp, nil, http.StatusOK)
+}
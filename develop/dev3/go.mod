@@ -1,3 +1,5 @@
 module dev3
 
 go 1.21.1
+
+require golang.org/x/text v0.14.0
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+/*
+output - место записи результата сортировки
+для -o результат пишется во временный файл рядом с FILE и переименовывается только после успешной сортировки,
+поэтому FILE может быть одним из входных файлов
+*/
+type output struct {
+	w    io.Writer
+	tmp  *os.File
+	path string
+}
+
+// openOutput - открывает вывод: стандартный вывод при пустом path, иначе файл
+func openOutput(path string) (*output, error) {
+	if path == "" {
+		return &output{w: os.Stdout}, nil
+	}
+
+	info, errStat := os.Stat(path)
+
+	// в устройства и каналы пишем напрямую, переименовать в них временный файл нельзя
+	if errStat == nil && !info.Mode().IsRegular() {
+		file, err := os.OpenFile(path, os.O_WRONLY, 0)
+
+		if err != nil {
+			return nil, fmt.Errorf("can not open file: %s", err.Error())
+		}
+
+		return &output{w: file, tmp: file}, nil
+	}
+
+	tmp, errCreate := os.CreateTemp(filepath.Dir(path), ".sort-*")
+
+	if errCreate != nil {
+		return nil, fmt.Errorf("can not open file: %s", errCreate.Error())
+	}
+
+	mode := os.FileMode(0644)
+	if errStat == nil {
+		mode = info.Mode().Perm()
+	}
+
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("can not open file: %s", err.Error())
+	}
+
+	return &output{w: tmp, tmp: tmp, path: path}, nil
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	return o.w.Write(p)
+}
+
+/*
+Close - завершает запись, при ошибке сортировки errSort временный файл удаляется и FILE остаётся нетронутым
+возвращает errSort или ошибку закрытия файла
+*/
+func (o *output) Close(errSort error) error {
+	if o.tmp == nil {
+		return errSort
+	}
+
+	errClose := o.tmp.Close()
+
+	if o.path == "" {
+		if errSort == nil && errClose != nil {
+			return fmt.Errorf("can not write to file: %s", errClose.Error())
+		}
+		return errSort
+	}
+
+	if errSort == nil && errClose == nil {
+		errClose = os.Rename(o.tmp.Name(), o.path)
+	}
+
+	if errSort != nil || errClose != nil {
+		os.Remove(o.tmp.Name())
+	}
+
+	if errSort == nil && errClose != nil {
+		return fmt.Errorf("can not write to file: %s", errClose.Error())
+	}
+
+	return errSort
+}
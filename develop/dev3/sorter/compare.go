@@ -0,0 +1,247 @@
+package sorter
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+Comparator - сравнение строк по ключам и модификаторам из Options
+keys []KeyDef - ключи с учётом глобальных модификаторов
+sep string - разделитель полей
+reverse bool - обратный порядок при сравнении строк целиком
+stable bool - не сравнивать строки целиком, если ключи равны, так же при -u, --count и --repeated
+seed uint64 - зерно хеша для модификатора R
+dateLayout string - макет time.Parse для ключей-дат
+collator *collator - сравнение текста по правилам локали, nil - побайтовое сравнение
+format string - формат записей
+columns map[string]int - номера столбцов csv и tsv по именам из заголовка
+*/
+type Comparator struct {
+	keys       []KeyDef
+	sep        string
+	reverse    bool
+	stable     bool
+	seed       uint64
+	dateLayout string
+	collator   *collator
+	format     string
+	columns    map[string]int
+}
+
+// NewComparator - создаёт сравнение строк по настройкам
+func NewComparator(opts Options) (*Comparator, error) {
+	opts, err := opts.validate()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Comparator{
+		keys:       opts.keys(),
+		sep:        opts.Separator,
+		reverse:    opts.Reverse,
+		stable:     opts.Stable || opts.grouped(),
+		seed:       opts.Seed,
+		dateLayout: opts.Date,
+		collator:   newCollator(opts.Locale),
+		format:     opts.Format,
+	}, nil
+}
+
+/*
+Compare - метод сравнения двух строк, возвращает -1, 0 или 1
+для csv и tsv ключи с именами столбцов требуют предварительного вызова SetHeader
+*/
+func (c *Comparator) Compare(a, b string) int {
+	return c.compareLines(c.prepare(a), c.prepare(b))
+}
+
+/*
+sortLine - строка вместе с заранее вычисленными значениями ключей
+line string - исходная строка
+keys []keyValue - значения ключей в порядке c.keys
+collated string - ключ сравнения всей строки в локали
+*/
+type sortLine struct {
+	line     string
+	keys     []keyValue
+	collated string
+}
+
+/*
+keyValue - значение ключа, подготовленное для сравнения
+text string - текст ключа после -d и -f, при заданной локали ключ сравнения в локали
+num float64 - число для -n, -g, -h и -M, для -h размер в байтах
+rank int - для -g строки без числа идут раньше NaN, NaN раньше чисел, для дат неразобранные раньше разобранных
+when time.Time - дата для Options.Date
+hash uint64 - для -R хеш текста ключа
+*/
+type keyValue struct {
+	text string
+	num  float64
+	rank int
+	hash uint64
+	when time.Time
+}
+
+// prepare - метод вычисляющий значения ключей строки
+func (c *Comparator) prepare(line string) sortLine {
+	item := sortLine{line: line, keys: make([]keyValue, len(c.keys))}
+
+	var rec record
+	if c.format != "" {
+		rec = c.parseRecord(line)
+	}
+
+	for i, key := range c.keys {
+		var text string
+		if key.Name != "" {
+			text = c.value(rec, key)
+		} else {
+			text = key.extract(line, c.sep)
+		}
+		kv := keyValue{text: text}
+
+		switch {
+		case key.Numeric:
+			kv.num = numericPrefix(text)
+		case key.General:
+			kv.num, kv.rank = generalNumeric(text)
+		case key.Human:
+			kv.num, _ = parseSize(strings.TrimLeft(text, " \t"))
+		case key.Months:
+			kv.num = float64(monthNumber(text))
+		case key.Date:
+			kv.when, kv.rank = parseDate(c.dateLayout, text)
+		default:
+			if key.Dictionary {
+				text = dictionaryOrder(text)
+			}
+			if key.Fold {
+				text = strings.ToUpper(text)
+			}
+			kv.text = text
+
+			if key.Random {
+				kv.hash = randomHash(c.seed, text)
+			}
+
+			if c.collator != nil && !key.Version {
+				kv.text = c.collator.key(text)
+			}
+		}
+
+		item.keys[i] = kv
+	}
+
+	if c.collator != nil {
+		item.collated = c.collator.key(line)
+	}
+
+	return item
+}
+
+/*
+compareLines - метод сравнения строк по ключам, ключи сравниваются по порядку до первого различия
+если все ключи равны, строки сравниваются целиком: сначала в локали, затем побайтово, с -s строки считаются равными
+*/
+func (c *Comparator) compareLines(a, b sortLine) int {
+	for i, key := range c.keys {
+		if res := compareKey(key, a.keys[i], b.keys[i]); res != 0 {
+			return res
+		}
+	}
+
+	if c.stable {
+		return 0
+	}
+
+	res := strings.Compare(a.collated, b.collated)
+
+	if res == 0 {
+		res = strings.Compare(a.line, b.line)
+	}
+
+	if c.reverse {
+		return -res
+	}
+
+	return res
+}
+
+// compareKey - сравнение значений ключа с учётом его модификаторов
+func compareKey(key KeyDef, a, b keyValue) int {
+	var c int
+
+	switch {
+	case key.Numeric || key.Months || key.Human:
+		c = compareFloats(a.num, b.num)
+	case key.General:
+		if c = compareInts(a.rank, b.rank); c == 0 {
+			c = compareFloats(a.num, b.num)
+		}
+	case key.Date:
+		if c = compareInts(a.rank, b.rank); c == 0 {
+			c = a.when.Compare(b.when)
+		}
+	case key.Random:
+		// одинаковые ключи дают одинаковый хеш и остаются рядом, при совпадении хешей разных ключей сравниваем текст
+		if c = compareHashes(a.hash, b.hash); c == 0 {
+			c = strings.Compare(a.text, b.text)
+		}
+	case key.Version:
+		c = compareVersions(a.text, b.text)
+	default:
+		c = strings.Compare(a.text, b.text)
+	}
+
+	if key.Reverse {
+		return -c
+	}
+
+	return c
+}
+
+// numericPrefix - число в начале строки после пробелов, строка без числа считается нулём
+func numericPrefix(s string) float64 {
+	s = strings.TrimLeft(s, " \t")
+	i := 0
+
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	for i < len(s) && isNumeric(s[i]) {
+		i++
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		for i < len(s) && isNumeric(s[i]) {
+			i++
+		}
+	}
+
+	val, err := strconv.ParseFloat(s[:i], 64)
+
+	if err != nil {
+		return 0
+	}
+
+	return val
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+func isNumeric(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,292 @@
+package sorter
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// lineOverhead - примерный расход памяти на хранение строки в слайсе помимо её байтов
+	lineOverhead = 16
+	// maxLineSize - максимальная длина строки, которую может прочитать сканер
+	maxLineSize = 64 << 20
+)
+
+/*
+sortStream - метод сортирующий строки из всех inputs вместе и записывающий результат в w
+если задан Options.BufferSize и данные не помещаются в буфер, отсортированные части
+сбрасываются во временные файлы и затем сливаются k-путевым слиянием
+*/
+func (s *sorter) sortStream(inputs []io.Reader, w io.Writer) error {
+	lw := &lineWriter{w: w}
+
+	// для --repeated нужно знать число повторов каждой строки, поэтому сортируем всё и выводим первые строки
+	if s.opts.Head > 0 && !s.opts.Repeated {
+		return s.sortHead(inputs, lw)
+	}
+
+	limit := s.opts.BufferSize
+
+	runs := make([]string, 0)
+	defer func() {
+		for _, run := range runs {
+			os.Remove(run)
+		}
+	}()
+
+	lines := make([]string, 0)
+	size := 0
+
+	errRead := s.readInputs(inputs, lw, func(line string) error {
+		lines = append(lines, line)
+		size += len(line) + lineOverhead
+
+		// буфер заполнен, сбрасываем отсортированную часть во временный файл
+		if limit > 0 && size >= limit {
+			run, err := s.writeRun(lines)
+
+			if err != nil {
+				return err
+			}
+			runs = append(runs, run)
+			lines = lines[:0]
+			size = 0
+		}
+
+		return nil
+	})
+
+	if errRead != nil {
+		return errRead
+	}
+
+	// всё поместилось в память, сортируем как раньше
+	if len(runs) == 0 {
+		items := s.prepareLines(lines)
+		s.parallelSort(items)
+
+		gw := newGroupWriter(lw, s.cmp, s.opts)
+		for _, item := range items {
+			if gw.done() {
+				break
+			}
+
+			if err := gw.add(item, 1); err != nil {
+				return err
+			}
+		}
+
+		return gw.flush()
+	}
+
+	if len(lines) > 0 {
+		run, err := s.writeRun(lines)
+
+		if err != nil {
+			return err
+		}
+		runs = append(runs, run)
+	}
+
+	return s.mergeRuns(runs, w)
+}
+
+/*
+readInputs - метод читающий записи из всех inputs по очереди и передающий их в fn
+заголовок csv и tsv первого входа пишется в lw, у остальных входов пропускается
+*/
+func (s *sorter) readInputs(inputs []io.Reader, lw *lineWriter, fn func(line string) error) error {
+	for i, r := range inputs {
+		scanner := s.newScanner(r)
+
+		if s.hasHeader() {
+			if err := s.readHeader(scanner, i == 0, lw); err != nil {
+				return err
+			}
+		}
+
+		for scanner.Scan() {
+			if err := fn(scanner.Text()); err != nil {
+				return err
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("can not read file: %s", err.Error())
+		}
+	}
+
+	return nil
+}
+
+// writeRun - метод сортирующий часть строк и записывающий её во временный файл, возвращает путь до файла
+func (s *sorter) writeRun(lines []string) (string, error) {
+	s.sorting(lines)
+
+	file, errCreate := os.CreateTemp(s.opts.TempDir, "sort-run-*")
+
+	if errCreate != nil {
+		return "", fmt.Errorf("can not create temporary file: %s", errCreate.Error())
+	}
+
+	bw := bufio.NewWriter(file)
+	for _, line := range lines {
+		bw.WriteString(line)
+		bw.WriteByte('\n')
+	}
+
+	errWrite := bw.Flush()
+
+	if errClose := file.Close(); errWrite == nil {
+		errWrite = errClose
+	}
+
+	if errWrite != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("can not write temporary file: %s", errWrite.Error())
+	}
+
+	return file.Name(), nil
+}
+
+// mergeRuns - метод сливающий отсортированные временные файлы в w
+func (s *sorter) mergeRuns(runs []string, w io.Writer) error {
+	inputs := make([]io.Reader, 0, len(runs))
+
+	for _, run := range runs {
+		file, err := os.Open(run)
+
+		if err != nil {
+			return fmt.Errorf("can not open temporary file: %s", err.Error())
+		}
+		defer file.Close()
+
+		inputs = append(inputs, file)
+	}
+
+	return s.mergeReaders(inputs, w, false)
+}
+
+/*
+mergeReaders - метод сливающий уже отсортированные входные данные в w, используется для временных файлов и Merge
+при равенстве строк первой выводится строка из более раннего входа,
+так что результат совпадает с устойчивой сортировкой в памяти
+header bool - входы начинаются с заголовка csv или tsv, во временных файлах заголовка нет
+*/
+func (s *sorter) mergeReaders(inputs []io.Reader, w io.Writer, header bool) error {
+	scanners := make([]recordScanner, len(inputs))
+	lw := &lineWriter{w: w}
+
+	for i, r := range inputs {
+		scanners[i] = s.newScanner(r)
+
+		if header {
+			if err := s.readHeader(scanners[i], i == 0, lw); err != nil {
+				return err
+			}
+		}
+	}
+
+	h := &runHeap{
+		less: func(a, b runLine) bool {
+			if c := s.cmp.compareLines(a.line, b.line); c != 0 {
+				return c < 0
+			}
+
+			return a.run < b.run
+		},
+	}
+
+	for i, sc := range scanners {
+		if sc.Scan() {
+			h.items = append(h.items, runLine{line: s.cmp.prepare(sc.Text()), run: i})
+		} else if err := sc.Err(); err != nil {
+			return fmt.Errorf("can not read file: %s", err.Error())
+		}
+	}
+	heap.Init(h)
+
+	gw := newGroupWriter(lw, s.cmp, s.opts)
+
+	for h.Len() > 0 && !gw.done() {
+		top := h.items[0]
+
+		if err := gw.add(top.line, 1); err != nil {
+			return err
+		}
+
+		sc := scanners[top.run]
+		if sc.Scan() {
+			h.items[0].line = s.cmp.prepare(sc.Text())
+			heap.Fix(h, 0)
+			continue
+		}
+
+		if err := sc.Err(); err != nil {
+			return fmt.Errorf("can not read file: %s", err.Error())
+		}
+		heap.Pop(h)
+	}
+
+	return gw.flush()
+}
+
+// newLineScanner - сканер строк с увеличенным максимальным размером строки
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	return scanner
+}
+
+// lineWriter - пишет строки, завершая каждую переводом строки
+type lineWriter struct {
+	w io.Writer
+}
+
+// WriteLine - метод записывающий очередную строку
+func (lw *lineWriter) WriteLine(line string) error {
+	if _, err := io.WriteString(lw.w, line); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(lw.w, "\n")
+
+	return err
+}
+
+/*
+runLine - очередная строка временного файла с вычисленными ключами
+run int - номер файла, в sortHead - номер строки во входе
+count int - в sortHead число строк с такими же ключами
+*/
+type runLine struct {
+	line  sortLine
+	run   int
+	count int
+}
+
+// runHeap - куча для k-путевого слияния временных файлов
+type runHeap struct {
+	items []runLine
+	less  func(a, b runLine) bool
+}
+
+func (h *runHeap) Len() int           { return len(h.items) }
+func (h *runHeap) Less(i, j int) bool { return h.less(h.items[i], h.items[j]) }
+func (h *runHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *runHeap) Push(x interface{}) {
+	h.items = append(h.items, x.(runLine))
+}
+
+func (h *runHeap) Pop() interface{} {
+	old := h.items
+	item := old[len(old)-1]
+	h.items = old[:len(old)-1]
+
+	return item
+}
@@ -0,0 +1,292 @@
+package sorter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// форматы входных данных для Options.Format, пустой формат - обычные строки
+const (
+	FormatCSV   = "csv"
+	FormatTSV   = "tsv"
+	FormatJSONL = "jsonl"
+)
+
+/*
+ParseNamedKey - разбирает ключ для Options.Format в виде NAME[:OPTS]
+NAME - имя или номер столбца для csv и tsv, путь вида .a.b.0 для jsonl
+*/
+func ParseNamedKey(s string) (KeyDef, error) {
+	name, opts := s, ""
+
+	if i := strings.LastIndexByte(s, ':'); i >= 0 && strings.Trim(s[i+1:], keyModifiers) == "" {
+		name, opts = s[:i], s[i+1:]
+	}
+
+	if name == "" {
+		return KeyDef{}, fmt.Errorf("invalid key %q: column name expected", s)
+	}
+
+	key := KeyDef{Name: name}
+	key.setOptions(opts, true)
+
+	return key, nil
+}
+
+// recordScanner - сканер записей входных данных: строк или записей csv
+type recordScanner interface {
+	Scan() bool
+	Text() string
+	Err() error
+}
+
+// newScanner - метод создающий сканер записей для формата Options.Format
+func (s *sorter) newScanner(r io.Reader) recordScanner {
+	switch s.opts.Format {
+	case FormatCSV:
+		return newCSVScanner(r, ',')
+	case FormatTSV:
+		return newCSVScanner(r, '\t')
+	}
+
+	return newLineScanner(r)
+}
+
+/*
+csvScanner - читает записи csv, поля в кавычках могут содержать разделители и переводы строк
+Text возвращает запись, заново закодированную в csv, поэтому её можно записать во временный файл и прочитать обратно
+*/
+type csvScanner struct {
+	r     *csv.Reader
+	comma rune
+	text  string
+	err   error
+}
+
+func newCSVScanner(r io.Reader, comma rune) *csvScanner {
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+
+	return &csvScanner{r: cr, comma: comma}
+}
+
+func (s *csvScanner) Scan() bool {
+	rec, err := s.r.Read()
+
+	if err == io.EOF {
+		return false
+	}
+	if err != nil {
+		s.err = err
+		return false
+	}
+
+	s.text, s.err = encodeCSV(rec, s.comma)
+
+	return s.err == nil
+}
+
+func (s *csvScanner) Text() string {
+	return s.text
+}
+
+func (s *csvScanner) Err() error {
+	return s.err
+}
+
+// encodeCSV - кодирует запись в csv без завершающего перевода строки
+func encodeCSV(rec []string, comma rune) (string, error) {
+	var buf bytes.Buffer
+
+	w := csv.NewWriter(&buf)
+	w.Comma = comma
+
+	if err := w.Write(rec); err != nil {
+		return "", err
+	}
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// hasHeader - первая запись каждого входа является заголовком
+func (s *sorter) hasHeader() bool {
+	return s.opts.Format == FormatCSV || s.opts.Format == FormatTSV
+}
+
+/*
+readHeader - метод читающий заголовок csv или tsv
+заголовок первого входа выводится первым и задаёт имена столбцов, заголовки остальных входов пропускаются
+*/
+func (s *sorter) readHeader(sc recordScanner, first bool, lw *lineWriter) error {
+	if !sc.Scan() {
+		if err := sc.Err(); err != nil {
+			return fmt.Errorf("can not read file: %s", err.Error())
+		}
+		return nil
+	}
+
+	if !first {
+		return nil
+	}
+
+	if err := s.cmp.SetHeader(sc.Text()); err != nil {
+		return err
+	}
+
+	return lw.WriteLine(sc.Text())
+}
+
+// SetHeader - метод задающий имена столбцов csv или tsv по строке заголовка, проверяет, что все ключи с именами найдены
+func (c *Comparator) SetHeader(header string) error {
+	names, err := c.parseCSV(header)
+
+	if err != nil {
+		return err
+	}
+
+	c.columns = make(map[string]int, len(names))
+	for i, name := range names {
+		if _, ok := c.columns[name]; !ok {
+			c.columns[name] = i
+		}
+	}
+
+	for _, key := range c.keys {
+		if key.Name == "" {
+			continue
+		}
+		if _, err := c.columnIndex(key.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// columnIndex - метод возвращающий номер столбца по имени из заголовка или по номеру, начиная с единицы
+func (c *Comparator) columnIndex(name string) (int, error) {
+	if i, ok := c.columns[name]; ok {
+		return i, nil
+	}
+
+	if n, err := strconv.Atoi(name); err == nil && n > 0 {
+		return n - 1, nil
+	}
+
+	return 0, fmt.Errorf("unknown column %q", name)
+}
+
+// parseCSV - метод разбирающий одну запись csv или tsv
+func (c *Comparator) parseCSV(text string) ([]string, error) {
+	cr := csv.NewReader(strings.NewReader(text))
+	cr.FieldsPerRecord = -1
+
+	if c.format == FormatTSV {
+		cr.Comma = '\t'
+	}
+
+	rec, err := cr.Read()
+
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("bad %s record: %s", c.format, err.Error())
+	}
+
+	return rec, nil
+}
+
+/*
+record - разобранная запись для ключей с именами
+fields []string - поля csv и tsv
+object interface{} - значение строки jsonl
+*/
+type record struct {
+	fields []string
+	object interface{}
+}
+
+// parseRecord - метод разбирающий запись, строка jsonl с ошибкой даёт пустые значения ключей
+func (c *Comparator) parseRecord(line string) record {
+	if c.format == FormatJSONL {
+		var obj interface{}
+
+		dec := json.NewDecoder(strings.NewReader(line))
+		dec.UseNumber()
+
+		if dec.Decode(&obj) != nil {
+			return record{}
+		}
+
+		return record{object: obj}
+	}
+
+	fields, _ := c.parseCSV(line)
+
+	return record{fields: fields}
+}
+
+// value - метод возвращающий значение ключа с именем, отсутствующее значение - пустая строка
+func (c *Comparator) value(rec record, key KeyDef) string {
+	var text string
+
+	if c.format == FormatJSONL {
+		text = jsonText(jsonPath(rec.object, key.Name))
+	} else if i, err := c.columnIndex(key.Name); err == nil && i < len(rec.fields) {
+		text = rec.fields[i]
+	}
+
+	if key.SkipStartBlanks {
+		return strings.TrimLeft(text, " \t")
+	}
+
+	return text
+}
+
+// jsonPath - значение по пути вида .a.b.0, элементы массивов адресуются номером
+func jsonPath(v interface{}, path string) interface{} {
+	for _, part := range strings.Split(strings.TrimPrefix(path, "."), ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			v = node[part]
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+
+			if err != nil || i < 0 || i >= len(node) {
+				return nil
+			}
+			v = node[i]
+		default:
+			return nil
+		}
+	}
+
+	return v
+}
+
+// jsonText - текст значения json для сравнения: строки без кавычек, числа как записаны, null - пустая строка
+func jsonText(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case bool:
+		return strconv.FormatBool(val)
+	}
+
+	data, _ := json.Marshal(v)
+
+	return string(data)
+}
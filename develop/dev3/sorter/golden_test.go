@@ -0,0 +1,74 @@
+package sorter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+/*
+TestGolden - сравнение с GNU sort, файл testdata/NAME.golden получен командой
+LC_ALL=C sort ARGS testdata/INPUT
+*/
+func TestGolden(t *testing.T) {
+	tsts := []struct {
+		name  string
+		args  string
+		input string
+		opts  Options
+	}{
+		{name: "words", args: "", input: "words.txt"},
+		{name: "words_reverse", args: "-r", input: "words.txt", opts: Options{Reverse: true}},
+		{name: "words_unique", args: "-u", input: "words.txt", opts: Options{Unique: true}},
+		{name: "words_fold", args: "-f", input: "words.txt", opts: Options{Fold: true}},
+		{name: "words_dictionary", args: "-d", input: "words.txt", opts: Options{Dictionary: true}},
+		{name: "words_numeric", args: "-n", input: "words.txt", opts: Options{Numeric: true}},
+		{name: "words_stable_fold", args: "-s -k1,1f", input: "words.txt", opts: Options{Stable: true, Keys: mustKeys("1,1f")}},
+		{name: "words_unique_fold", args: "-u -f", input: "words.txt", opts: Options{Unique: true, Fold: true}},
+		{name: "table_numeric_key", args: "-k2,2n", input: "table.txt", opts: Options{Keys: mustKeys("2,2n")}},
+		{name: "table_several_keys", args: "-k2,2nr -k1,1", input: "table.txt", opts: Options{Keys: mustKeys("2,2nr", "1,1")}},
+		{name: "table_months", args: "-k3,3M", input: "table.txt", opts: Options{Keys: mustKeys("3,3M")}},
+		{name: "table_months_reverse", args: "-k3,3Mr", input: "table.txt", opts: Options{Keys: mustKeys("3,3Mr")}},
+		{name: "table_human", args: "-k4,4h", input: "table.txt", opts: Options{Keys: mustKeys("4,4h")}},
+		{name: "table_separator", args: "-t: -k2,2n -k3,3", input: "table.txt", opts: Options{Separator: ":", Keys: mustKeys("2,2n", "3,3")}},
+		{name: "table_chars", args: "-k1.2,1.3", input: "table.txt", opts: Options{Keys: mustKeys("1.2,1.3")}},
+		{name: "table_stable", args: "-s -k2,2n", input: "table.txt", opts: Options{Stable: true, Keys: mustKeys("2,2n")}},
+		{name: "table_unique_key", args: "-u -k2,2n", input: "table.txt", opts: Options{Unique: true, Keys: mustKeys("2,2n")}},
+		{name: "table_blanks", args: "-k1b,1", input: "table.txt", opts: Options{Keys: mustKeys("1b,1")}},
+		{name: "sizes_human", args: "-h", input: "sizes.txt", opts: Options{Human: true}},
+		{name: "sizes_human_reverse", args: "-hr", input: "sizes.txt", opts: Options{Human: true, Reverse: true}},
+		{name: "numbers_numeric", args: "-n", input: "numbers.txt", opts: Options{Numeric: true}},
+		{name: "numbers_general", args: "-g", input: "numbers.txt", opts: Options{General: true}},
+		{name: "versions", args: "-V", input: "versions.txt", opts: Options{Version: true}},
+		{name: "versions_key", args: "-t- -k2V -k1,1", input: "versions.txt", opts: Options{Separator: "-", Keys: mustKeys("2V", "1,1")}},
+		{name: "trailing_key", args: "-k2,2", input: "trailing.txt", opts: Options{Keys: mustKeys("2,2")}},
+		// у GNU sort нет --date, эти файлы получены сортировкой по частям даты -k2.8,2.11n -k2.5,2.6n -k2.2,2.3n, для r - с nr
+		{name: "dates_key_reverse", args: "--date 02/01/2006 -k2,2r", input: "dates.txt", opts: Options{Date: "02/01/2006", Keys: mustKeys("2,2r")}},
+		{name: "dates_key_blanks", args: "--date 02/01/2006 -k2b,2", input: "dates.txt", opts: Options{Date: "02/01/2006", Keys: mustKeys("2b,2")}},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := os.Open(filepath.Join("testdata", tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer input.Close()
+
+			want, err := os.ReadFile(filepath.Join("testdata", tt.name+".golden"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got bytes.Buffer
+			if err := Sort(input, &got, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("sort %s %s\ngot:\n%s\nwant:\n%s", tt.args, tt.input, got.String(), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package sorter
+
+import (
+	"container/heap"
+	"io"
+	"sort"
+)
+
+/*
+sortHead - метод выводящий только первые Options.Head строк отсортированного результата
+строки не сортируются целиком: в куче хранятся Head лучших строк, сверху худшая из них,
+новая строка попадает в кучу, только если она лучше худшей, поэтому память не зависит от размера входа
+*/
+func (s *sorter) sortHead(inputs []io.Reader, lw *lineWriter) error {
+	// seq - номер строки во входе, при равенстве раньше идёт более ранняя строка, как при устойчивой сортировке
+	h := &runHeap{
+		less: func(a, b runLine) bool {
+			if c := s.cmp.compareLines(a.line, b.line); c != 0 {
+				return c > 0
+			}
+
+			return a.run > b.run
+		},
+	}
+
+	seq := 0
+	grouped := s.opts.grouped()
+
+	err := s.readInputs(inputs, lw, func(line string) error {
+		item := runLine{line: s.cmp.prepare(line), run: seq, count: 1}
+		seq++
+
+		full := h.Len() == s.opts.Head
+
+		// для -u и --count строка с теми же ключами, что у строки в куче, только увеличивает её счётчик,
+		// такая строка не может быть хуже худшей строки кучи, поэтому строки хуже неё не ищем
+		if grouped && (!full || s.cmp.compareLines(item.line, h.items[0].line) <= 0) {
+			for i := range h.items {
+				if s.cmp.compareLines(h.items[i].line, item.line) == 0 {
+					h.items[i].count++
+					return nil
+				}
+			}
+		}
+
+		if !full {
+			heap.Push(h, item)
+			return nil
+		}
+
+		if h.less(h.items[0], item) {
+			h.items[0] = item
+			heap.Fix(h, 0)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(h.items, func(i, j int) bool {
+		return h.less(h.items[j], h.items[i])
+	})
+
+	gw := newGroupWriter(lw, s.cmp, s.opts)
+	for _, item := range h.items {
+		if err := gw.add(item.line, item.count); err != nil {
+			return err
+		}
+	}
+
+	return gw.flush()
+}
@@ -0,0 +1,274 @@
+package sorter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+KeyDef - ключ сортировки, задаётся как -k POS1[,POS2], где POS имеет вид F[.C][OPTS]
+StartField, StartChar int - поле и символ начала ключа, нумерация с единицы
+EndField int - поле конца ключа, 0 - ключ до конца строки
+EndChar int - последний символ ключа, считая от начала поля EndField, 0 - до конца поля
+SkipStartBlanks, SkipEndBlanks bool - модификатор b для начала и конца ключа
+Numeric, Human, Months, Fold, Reverse bool - модификаторы n, h, M, f, r
+Dictionary, General, Version, Random bool - модификаторы d, g, V, R
+Date bool - сравнение как даты по макету Options.Date, своей буквы у модификатора нет, ставится для всех ключей без n, h, M, g, V, R
+Name string - имя столбца или путь JSON для Options.Format, тогда позиции не используются
+*/
+type KeyDef struct {
+	Name            string
+	StartField      int
+	StartChar       int
+	EndField        int
+	EndChar         int
+	SkipStartBlanks bool
+	SkipEndBlanks   bool
+	Numeric         bool
+	Human           bool
+	Months          bool
+	Fold            bool
+	Reverse         bool
+	Dictionary      bool
+	General         bool
+	Version         bool
+	Random          bool
+	Date            bool
+}
+
+// ParseKeyDef - разбирает определение ключа в формате POSIX, например 2,2n или 1.3b,1.5r
+func ParseKeyDef(s string) (KeyDef, error) {
+	key := KeyDef{}
+	pos1, pos2, hasEnd := strings.Cut(s, ",")
+
+	field, char, hasChar, opts, err := parseKeyPos(pos1)
+
+	if err != nil {
+		return KeyDef{}, fmt.Errorf("invalid key %q: %s", s, err.Error())
+	}
+	if field == 0 {
+		return KeyDef{}, fmt.Errorf("invalid key %q: field number is zero", s)
+	}
+	if hasChar && char == 0 {
+		return KeyDef{}, fmt.Errorf("invalid key %q: character offset is zero", s)
+	}
+	if !hasChar {
+		char = 1
+	}
+
+	key.StartField, key.StartChar = field, char
+	key.setOptions(opts, true)
+
+	if !hasEnd {
+		return key, nil
+	}
+
+	field, char, _, opts, err = parseKeyPos(pos2)
+
+	if err != nil {
+		return KeyDef{}, fmt.Errorf("invalid key %q: %s", s, err.Error())
+	}
+	if field == 0 {
+		return KeyDef{}, fmt.Errorf("invalid key %q: field number is zero", s)
+	}
+
+	key.EndField, key.EndChar = field, char
+	key.setOptions(opts, false)
+
+	return key, nil
+}
+
+// keyModifiers - допустимые модификаторы ключа
+const keyModifiers = "bdfghMnRrV"
+
+// parseKeyPos - разбирает позицию F[.C][OPTS]
+func parseKeyPos(s string) (field, char int, hasChar bool, opts string, err error) {
+	i := 0
+	for i < len(s) && isNumeric(s[i]) {
+		i++
+	}
+
+	if i == 0 {
+		return 0, 0, false, "", fmt.Errorf("field number expected")
+	}
+
+	if field, err = strconv.Atoi(s[:i]); err != nil {
+		return 0, 0, false, "", fmt.Errorf("bad field number %s", s[:i])
+	}
+
+	if i < len(s) && s[i] == '.' {
+		j := i + 1
+		for j < len(s) && isNumeric(s[j]) {
+			j++
+		}
+
+		if j == i+1 {
+			return 0, 0, false, "", fmt.Errorf("character offset expected")
+		}
+
+		if char, err = strconv.Atoi(s[i+1 : j]); err != nil {
+			return 0, 0, false, "", fmt.Errorf("bad character offset %s", s[i+1:j])
+		}
+		hasChar = true
+		i = j
+	}
+
+	opts = s[i:]
+	for _, o := range opts {
+		if !strings.ContainsRune(keyModifiers, o) {
+			return 0, 0, false, "", fmt.Errorf("unknown modifier %q", o)
+		}
+	}
+
+	return field, char, hasChar, opts, nil
+}
+
+// setOptions - устанавливает модификаторы ключа, b относится к той позиции, в которой указан
+func (k *KeyDef) setOptions(opts string, start bool) {
+	for _, o := range opts {
+		switch o {
+		case 'b':
+			if start {
+				k.SkipStartBlanks = true
+			} else {
+				k.SkipEndBlanks = true
+			}
+		case 'n':
+			k.Numeric = true
+		case 'r':
+			k.Reverse = true
+		case 'M':
+			k.Months = true
+		case 'h':
+			k.Human = true
+		case 'f':
+			k.Fold = true
+		case 'd':
+			k.Dictionary = true
+		case 'g':
+			k.General = true
+		case 'V':
+			k.Version = true
+		case 'R':
+			k.Random = true
+		}
+	}
+}
+
+// hasOptions - у ключа есть собственные модификаторы, тогда глобальные модификаторы к нему не применяются
+// hasMode - у ключа есть собственный способ сравнения вместо текстового, тогда Options.Date на него не действует
+func (k KeyDef) hasMode() bool {
+	return k.Numeric || k.Human || k.Months || k.General || k.Version || k.Random
+}
+
+func (k KeyDef) hasOptions() bool {
+	return k.SkipStartBlanks || k.SkipEndBlanks || k.Numeric || k.Human || k.Months || k.Fold || k.Reverse ||
+		k.Dictionary || k.General || k.Version || k.Random || k.Date
+}
+
+/*
+extract - возвращает часть строки, попадающую в ключ
+sep string - разделитель полей, при пустом поля разделяются пробелами и включают ведущие пробелы
+*/
+func (k KeyDef) extract(line, sep string) string {
+	fields := fieldBounds(line, sep)
+	beg := len(line)
+
+	if k.StartField-1 < len(fields) {
+		beg = fields[k.StartField-1][0]
+
+		if k.SkipStartBlanks {
+			for beg < len(line) && isBlank(line[beg]) {
+				beg++
+			}
+		}
+		beg = min(len(line), beg+k.StartChar-1)
+	}
+
+	end := len(line)
+
+	if k.EndField > 0 && k.EndField-1 < len(fields) {
+		f := fields[k.EndField-1]
+		end = f[1]
+
+		if k.EndChar > 0 {
+			end = f[0]
+
+			if k.SkipEndBlanks {
+				for end < len(line) && isBlank(line[end]) {
+					end++
+				}
+			}
+			end = min(len(line), end+k.EndChar)
+		}
+	}
+
+	if end <= beg {
+		return ""
+	}
+
+	return line[beg:end]
+}
+
+// fieldBounds - границы полей строки в виде полуинтервалов [начало, конец)
+func fieldBounds(line, sep string) [][2]int {
+	fields := make([][2]int, 0, 8)
+
+	if sep != "" {
+		start := 0
+		for {
+			i := strings.Index(line[start:], sep)
+
+			if i < 0 {
+				return append(fields, [2]int{start, len(line)})
+			}
+
+			fields = append(fields, [2]int{start, start + i})
+			start += i + len(sep)
+		}
+	}
+
+	start := 0
+	for start < len(line) {
+		i := start
+		for i < len(line) && isBlank(line[i]) {
+			i++
+		}
+
+		// пробелы в конце строки - последнее поле, как в GNU sort
+		for i < len(line) && !isBlank(line[i]) {
+			i++
+		}
+
+		fields = append(fields, [2]int{start, i})
+		start = i
+	}
+
+	return fields
+}
+
+// ParseKeys - разбирает определения ключей: при заданном формате как NAME[:OPTS], иначе как KEYDEF
+func ParseKeys(defs []string, format string) ([]KeyDef, error) {
+	keys := make([]KeyDef, 0, len(defs))
+
+	for _, def := range defs {
+		parse := ParseKeyDef
+		if format != "" {
+			parse = ParseNamedKey
+		}
+
+		key, err := parse(def)
+
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t'
+}
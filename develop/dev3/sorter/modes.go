@@ -0,0 +1,277 @@
+package sorter
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// generalNumber - число с плавающей точкой в начале строки, как его понимает strtod
+var generalNumber = regexp.MustCompile(`^[+-]?(?i:inf(?:inity)?|nan|0x(?:[0-9a-f]+\.?[0-9a-f]*|\.[0-9a-f]+)(?:p[+-]?[0-9]+)?|(?:[0-9]+\.?[0-9]*|\.[0-9]+)(?:e[+-]?[0-9]+)?)`)
+
+const (
+	// порядок значений для -g: строки без числа, затем NaN, затем числа
+	rankNotNumber = iota
+	rankNaN
+	rankNumber
+)
+
+// generalNumeric - значение для -g и его ранг
+func generalNumeric(s string) (float64, int) {
+	s = strings.TrimLeft(s, " \t")
+	num := generalNumber.FindString(s)
+
+	if num == "" {
+		return 0, rankNotNumber
+	}
+
+	// ParseFloat требует двоичный порядок у шестнадцатеричных чисел
+	if strings.ContainsAny(num, "xX") && !strings.ContainsAny(num, "pP") {
+		num += "p0"
+	}
+
+	val, err := strconv.ParseFloat(num, 64)
+
+	// ParseFloat возвращает ошибку при переполнении, но значение ±Inf при этом верное
+	if err != nil && !math.IsInf(val, 0) {
+		return 0, rankNotNumber
+	}
+	if math.IsNaN(val) {
+		return 0, rankNaN
+	}
+
+	return val, rankNumber
+}
+
+// dictionaryOrder - оставляет в строке только буквы, цифры и пробелы (-d)
+func dictionaryOrder(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == ' ' || r == '\t' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+/*
+compareVersions - сравнение строк с номерами версий (-V), v1.9 < v1.10
+строка делится на нецифровые и цифровые части, цифровые сравниваются как числа,
+в нецифровых буквы идут раньше остальных символов, а ~ раньше всего, даже конца строки
+*/
+func compareVersions(a, b string) int {
+	for a != "" || b != "" {
+		// нецифровые части
+		for (a != "" && !isNumeric(a[0])) || (b != "" && !isNumeric(b[0])) {
+			ca, cb := versionOrder(a), versionOrder(b)
+
+			if ca != cb {
+				return compareInts(ca, cb)
+			}
+			a, b = a[1:], b[1:]
+		}
+
+		// цифровые части, ведущие нули не учитываются
+		a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+		na, nb := digitsLen(a), digitsLen(b)
+
+		if na != nb {
+			return compareInts(na, nb)
+		}
+		if c := strings.Compare(a[:na], b[:nb]); c != 0 {
+			return c
+		}
+		a, b = a[na:], b[nb:]
+	}
+
+	return 0
+}
+
+// versionOrder - вес первого символа нецифровой части версии
+func versionOrder(s string) int {
+	switch {
+	case s == "" || isNumeric(s[0]):
+		return 0
+	case s[0] == '~':
+		return -1
+	case s[0] >= 'a' && s[0] <= 'z' || s[0] >= 'A' && s[0] <= 'Z':
+		return int(s[0])
+	}
+
+	return int(s[0]) + 256
+}
+
+func digitsLen(s string) int {
+	i := 0
+	for i < len(s) && isNumeric(s[i]) {
+		i++
+	}
+
+	return i
+}
+
+/*
+monthNames - первые три буквы названий месяцев в нижнем регистре, английские и русские,
+у мая русское название и в именительном, и в родительном падеже
+*/
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	"янв": 1, "фев": 2, "мар": 3, "апр": 4, "май": 5, "мая": 5, "июн": 6,
+	"июл": 7, "авг": 8, "сен": 9, "окт": 10, "ноя": 11, "дек": 12,
+}
+
+/*
+monthNumber - номер месяца по первым трём буквам названия в любом регистре: JAN, jan, January, января
+неизвестное название считается меньше января
+*/
+func monthNumber(s string) int {
+	s = strings.TrimLeft(s, " \t")
+
+	end := 0
+	for i := 0; i < 3 && end < len(s); i++ {
+		_, size := utf8.DecodeRuneInString(s[end:])
+		end += size
+	}
+
+	return monthNames[strings.ToLower(s[:end])]
+}
+
+// dateLayouts - имена макетов для Options.Date
+var dateLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+/*
+parseDate - дата из ключа по макету и её ранг для сравнения
+ключ, который не удалось разобрать, получает ранг 0 и идёт раньше всех дат
+*/
+func parseDate(layout, s string) (time.Time, int) {
+	date, err := time.Parse(layout, strings.Trim(s, " \t"))
+
+	if err != nil {
+		return time.Time{}, 0
+	}
+
+	return date, 1
+}
+
+/*
+randomHash - хеш ключа для -R, FNV-1a с зерном и перемешиванием битов в конце
+при одном зерне одинаковые ключи получают одинаковый хеш, поэтому порядок воспроизводим
+*/
+func randomHash(seed uint64, s string) uint64 {
+	h := uint64(14695981039346656037) ^ seed
+
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= 1099511628211
+	}
+
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+
+	return h
+}
+
+func compareHashes(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+/*
+collator - ключи сравнения строк по правилам локали
+collate.Collator нельзя использовать из нескольких горутин, поэтому они хранятся в пуле
+*/
+type collator struct {
+	pool sync.Pool
+}
+
+// newCollator - создаёт collator для локали, для пустой, C и POSIX возвращает nil: строки сравниваются побайтово
+func newCollator(locale string) *collator {
+	tag, ok, err := localeTag(locale)
+
+	if !ok || err != nil {
+		return nil
+	}
+
+	return &collator{
+		pool: sync.Pool{
+			New: func() interface{} {
+				return collate.New(tag)
+			},
+		},
+	}
+}
+
+/*
+localeTag - приводит имя локали вида ru_RU.UTF-8 к тегу языка
+ok false для пустой локали, C и POSIX
+*/
+func localeTag(locale string) (language.Tag, bool, error) {
+	name, _, _ := strings.Cut(locale, ".")
+	name, _, _ = strings.Cut(name, "@")
+
+	if name == "" || name == "C" || name == "POSIX" {
+		return language.Und, false, nil
+	}
+
+	tag, err := language.Parse(strings.ReplaceAll(name, "_", "-"))
+
+	if err != nil {
+		return language.Und, false, fmt.Errorf("unknown locale %q", locale)
+	}
+
+	return tag, true, nil
+}
+
+// key - ключ сравнения строки: побайтовое сравнение ключей соответствует сравнению строк в локали
+func (c *collator) key(s string) string {
+	col := c.pool.Get().(*collate.Collator)
+	defer c.pool.Put(col)
+
+	var buf collate.Buffer
+
+	return string(col.KeyFromString(&buf, s))
+}
@@ -0,0 +1,173 @@
+package sorter
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"unicode/utf8"
+)
+
+/*
+Options - настройки сортировки
+Keys []KeyDef - ключи сортировки, без ключей сравниваются строки целиком
+Separator string - разделитель полей, пустой - поля разделяются пробелами
+IgnoreBlanks, Numeric, Human, Months, Fold, Dictionary, General, Version, Random, Reverse bool - глобальные модификаторы
+b, n, h, M, f, d, g, V, R, r, действуют на ключи без собственных модификаторов
+Stable bool - не сравнивать строки целиком при равных ключах, равные строки остаются в порядке ввода
+Unique bool - выводить только первую из строк с равными ключами, при проверке требовать строгий порядок
+Count bool - как uniq -c: выводить первую из строк с равными ключами с числом таких строк перед ней
+Repeated bool - выводить только строки, ключи которых встретились больше одного раза
+Seed uint64 - зерно хеша для R, 0 - случайное
+Head int - выводить только первые Head строк результата, 0 - все строки
+Date string - сравнивать как даты ключи без собственного способа сравнения n, h, M, g, V, R, макет time.Parse или имя вроде RFC3339
+Locale string - локаль для сравнения текста, пустая или C - побайтовое сравнение
+Format string - формат записей: пусто, FormatCSV, FormatTSV или FormatJSONL
+BufferSize int - размер буфера в байтах, при превышении используются временные файлы, 0 - без ограничения
+TempDir string - каталог для временных файлов, пустой - os.TempDir()
+Parallel int - число горутин для сортировки, 0 - DefaultParallel()
+*/
+type Options struct {
+	Keys         []KeyDef
+	Separator    string
+	IgnoreBlanks bool
+	Numeric      bool
+	Human        bool
+	Months       bool
+	Fold         bool
+	Dictionary   bool
+	General      bool
+	Version      bool
+	Random       bool
+	Reverse      bool
+	Stable       bool
+	Unique       bool
+	Count        bool
+	Repeated     bool
+	Seed         uint64
+	Head         int
+	Date         string
+	Locale       string
+	Format       string
+	BufferSize   int
+	TempDir      string
+	Parallel     int
+}
+
+// validate - проверяет настройки и подставляет значения по умолчанию
+func (o Options) validate() (Options, error) {
+	if utf8.RuneCountInString(o.Separator) > 1 {
+		return o, fmt.Errorf("multi-character tab %q", o.Separator)
+	}
+
+	switch o.Format {
+	case "", FormatCSV, FormatTSV, FormatJSONL:
+	default:
+		return o, fmt.Errorf("unknown format %q, expected csv, tsv or jsonl", o.Format)
+	}
+
+	if _, _, err := localeTag(o.Locale); err != nil {
+		return o, err
+	}
+
+	if o.Parallel < 0 {
+		return o, fmt.Errorf("invalid number of parallel sorts %d", o.Parallel)
+	}
+	if o.Parallel == 0 {
+		o.Parallel = DefaultParallel()
+	}
+
+	if layout, ok := dateLayouts[o.Date]; ok {
+		o.Date = layout
+	}
+
+	if o.Head < 0 {
+		return o, fmt.Errorf("invalid number of lines %d", o.Head)
+	}
+
+	// зерно выбирается один раз, чтобы все части и временные файлы перемешивались одинаково
+	if o.Seed == 0 {
+		o.Seed = rand.Uint64()
+	}
+
+	return o, nil
+}
+
+// grouped - строки с равными ключами схлопываются в одну
+func (o Options) grouped() bool {
+	return o.Unique || o.Count || o.Repeated
+}
+
+/*
+keys - ключи сортировки с учётом глобальных модификаторов
+ключ без собственных модификаторов наследует глобальные, без ключей ключом служит вся строка
+у дат нет своей буквы, поэтому Date действует и на ключи с модификаторами вроде r или b, если у них нет
+собственного способа сравнения
+*/
+func (o Options) keys() []KeyDef {
+	keys := append([]KeyDef(nil), o.Keys...)
+
+	if len(keys) == 0 {
+		keys = append(keys, KeyDef{StartField: 1, StartChar: 1})
+	}
+
+	for i := range keys {
+		if !keys[i].hasOptions() {
+			keys[i].SkipStartBlanks = o.IgnoreBlanks
+			keys[i].SkipEndBlanks = o.IgnoreBlanks
+			keys[i].Numeric = o.Numeric
+			keys[i].Human = o.Human
+			keys[i].Months = o.Months
+			keys[i].Reverse = o.Reverse
+			keys[i].Fold = o.Fold
+			keys[i].Dictionary = o.Dictionary
+			keys[i].General = o.General
+			keys[i].Version = o.Version
+			keys[i].Random = o.Random
+		}
+
+		keys[i].Date = o.Date != "" && !keys[i].hasMode()
+	}
+
+	return keys
+}
+
+/*
+ParseBufferSize - разбирает размер буфера вида 512K, 100M, 1G
+число без суффикса считается в килобайтах, суффикс b означает байты, пустая строка - без ограничения
+*/
+func ParseBufferSize(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	mul := uint64(1 << 10)
+	num := s[:len(s)-1]
+
+	switch s[len(s)-1] {
+	case 'b':
+		mul = 1
+	case 'k', 'K':
+		mul = 1 << 10
+	case 'm', 'M':
+		mul = 1 << 20
+	case 'g', 'G':
+		mul = 1 << 30
+	case 't', 'T':
+		mul = 1 << 40
+	default:
+		num = s
+	}
+
+	val, err := strconv.ParseUint(num, 10, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid buffer size: %s", s)
+	}
+
+	if val > math.MaxInt/mul {
+		return 0, fmt.Errorf("buffer size is too large: %s", s)
+	}
+
+	return int(val * mul), nil
+}
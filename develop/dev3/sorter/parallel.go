@@ -0,0 +1,146 @@
+package sorter
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// minChunkLines - меньше строк на горутину не делим, накладные расходы превысят выигрыш
+const minChunkLines = 1 << 14
+
+// DefaultParallel - число горутин по умолчанию, как в GNU sort не больше 8
+func DefaultParallel() int {
+	return min(runtime.NumCPU(), 8)
+}
+
+/*
+sorting - метод для сортировки строк
+ключи каждой строки вычисляются один раз, затем строки сортируются параллельно
+*/
+func (s *sorter) sorting(lines []string) {
+	items := s.prepareLines(lines)
+	s.parallelSort(items)
+
+	for i, item := range items {
+		lines[i] = item.line
+	}
+}
+
+/*
+parallelSort - метод сортирующий строки в несколько горутин
+строки делятся на Options.Parallel частей, каждая сортируется отдельно, затем части попарно сливаются,
+при равенстве первой идёт строка из левой части, поэтому с -s результат совпадает с sort.SliceStable
+*/
+func (s *sorter) parallelSort(items []sortLine) {
+	workers := min(s.opts.Parallel, len(items)/minChunkLines)
+
+	if workers <= 1 {
+		s.sortChunk(items)
+		return
+	}
+
+	bounds := make([]int, 0, workers+1)
+	for i := 0; i <= workers; i++ {
+		bounds = append(bounds, i*len(items)/workers)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(chunk []sortLine) {
+			defer wg.Done()
+			s.sortChunk(chunk)
+		}(items[bounds[i]:bounds[i+1]])
+	}
+	wg.Wait()
+
+	src, dst := items, make([]sortLine, len(items))
+
+	// каждый проход сливает соседние пары частей, пока не останется одна
+	for len(bounds) > 2 {
+		next := make([]int, 0, len(bounds)/2+1)
+
+		for i := 0; i+1 < len(bounds); i += 2 {
+			lo := bounds[i]
+			next = append(next, lo)
+
+			if i+2 >= len(bounds) {
+				copy(dst[lo:], src[lo:bounds[i+1]])
+				continue
+			}
+
+			mid, hi := bounds[i+1], bounds[i+2]
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.mergeChunks(dst[lo:hi], src[lo:mid], src[mid:hi])
+			}()
+		}
+		next = append(next, len(items))
+		wg.Wait()
+
+		src, dst, bounds = dst, src, next
+	}
+
+	if &src[0] != &items[0] {
+		copy(items, src)
+	}
+}
+
+// prepareLines - метод вычисляющий значения ключей для всех строк, строки делятся между Options.Parallel горутинами
+func (s *sorter) prepareLines(lines []string) []sortLine {
+	items := make([]sortLine, len(lines))
+	workers := max(1, min(s.opts.Parallel, len(lines)/minChunkLines))
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(lo, hi int) {
+			defer wg.Done()
+			for i := lo; i < hi; i++ {
+				items[i] = s.cmp.prepare(lines[i])
+			}
+		}(w*len(lines)/workers, (w+1)*len(lines)/workers)
+	}
+	wg.Wait()
+
+	return items
+}
+
+/*
+sortChunk - метод для сортировки части строк в одной горутине
+без -s, -u, --count и --repeated равны только одинаковые строки, поэтому устойчивая сортировка не нужна
+*/
+func (s *sorter) sortChunk(items []sortLine) {
+	less := func(i, j int) bool {
+		return s.cmp.compareLines(items[i], items[j]) < 0
+	}
+
+	if s.cmp.stable {
+		sort.SliceStable(items, less)
+		return
+	}
+
+	sort.Slice(items, less)
+}
+
+// mergeChunks - метод сливающий отсортированные части left и right в dst
+func (s *sorter) mergeChunks(dst, left, right []sortLine) {
+	i, j, k := 0, 0, 0
+
+	for i < len(left) && j < len(right) {
+		if s.cmp.compareLines(right[j], left[i]) < 0 {
+			dst[k] = right[j]
+			j++
+		} else {
+			dst[k] = left[i]
+			i++
+		}
+		k++
+	}
+
+	k += copy(dst[k:], left[i:])
+	copy(dst[k:], right[j:])
+}
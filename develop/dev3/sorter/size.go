@@ -0,0 +1,96 @@
+package sorter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits - множители единиц для -h по порядку, K - 10^3 или 2^10 для Ki, M - 10^6 или 2^20 для Mi и т.д.
+const sizeUnits = "KMGTPEZYRQ"
+
+/*
+ParseSize - разбирает размер вида 1.5G, 512Ki, 20MiB, 3kB или 100B
+число может быть дробным и отрицательным, единицы СИ (K, M, G, ...) кратны 1000,
+двоичные единицы МЭК (Ki, Mi, Gi, ...) кратны 1024, суффикс B необязателен, k можно писать строчной
+*/
+func ParseSize(s string) (float64, error) {
+	s = strings.TrimLeft(s, " \t")
+	val, n := parseSize(s)
+
+	if n == 0 || n != len(s) {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	return val, nil
+}
+
+/*
+parseSize - разбирает размер в начале строки, возвращает значение и число разобранных байт
+строка без числа даёт 0 и n == 0, как и в GNU sort неразобранный хвост строки не учитывается
+*/
+func parseSize(s string) (float64, int) {
+	i := 0
+
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+
+	start := i
+	for i < len(s) && isNumeric(s[i]) {
+		i++
+	}
+	digits := i - start
+
+	if i < len(s) && s[i] == '.' {
+		j := i + 1
+		for j < len(s) && isNumeric(s[j]) {
+			j++
+		}
+
+		// точка без цифр ни до, ни после неё не часть числа
+		if digits > 0 || j > i+1 {
+			digits += j - i - 1
+			i = j
+		}
+	}
+
+	if digits == 0 {
+		return 0, 0
+	}
+
+	// ошибка возможна только при переполнении, тогда val равен ±Inf
+	val, _ := strconv.ParseFloat(s[:i], 64)
+
+	if i < len(s) && s[i] == 'B' {
+		return val, i + 1
+	}
+
+	if i >= len(s) {
+		return val, i
+	}
+
+	unit := s[i]
+	if unit == 'k' {
+		unit = 'K'
+	}
+
+	order := strings.IndexByte(sizeUnits, unit)
+
+	if order < 0 {
+		return val, i
+	}
+	i++
+
+	base := 1000.0
+	if i < len(s) && s[i] == 'i' {
+		base = 1024
+		i++
+	}
+	if i < len(s) && s[i] == 'B' {
+		i++
+	}
+
+	return val * math.Pow(base, float64(order+1)), i
+}
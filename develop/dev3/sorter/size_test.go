@@ -0,0 +1,87 @@
+package sorter
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tsts := []struct {
+		name    string
+		arg     string
+		want    float64
+		wantErr bool
+	}{
+		{name: "plain", arg: "512", want: 512},
+		{name: "bytes", arg: "100B", want: 100},
+		{name: "si", arg: "2K", want: 2e3},
+		{name: "lower case k", arg: "3kB", want: 3e3},
+		{name: "decimal", arg: "1.5G", want: 1.5e9},
+		{name: "iec", arg: "1Ki", want: 1024},
+		{name: "iec with b", arg: "20MiB", want: 20 << 20},
+		{name: "fraction only", arg: ".5M", want: 5e5},
+		{name: "negative", arg: "-2K", want: -2e3},
+		{name: "leading blanks", arg: "  7T", want: 7e12},
+		{name: "empty", arg: "", wantErr: true},
+		{name: "no number", arg: "K", wantErr: true},
+		{name: "only dot", arg: ".", wantErr: true},
+		{name: "unknown unit", arg: "5X", wantErr: true},
+		{name: "lower case m", arg: "5m", wantErr: true},
+		{name: "trailing text", arg: "5KiBs", wantErr: true},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSize(tt.arg)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSize(%q) error = %v, wantErr %v", tt.arg, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSize(%q) = %g, want %g", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzParseSize(f *testing.F) {
+	for _, seed := range []string{"1.5G", "512Ki", "20MiB", "3kB", "100B", "-0.5T", "abc", "1e5", "..", "-"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		val, n := parseSize(s)
+
+		if n < 0 || n > len(s) {
+			t.Fatalf("parseSize(%q) consumed %d bytes", s, n)
+		}
+		if n == 0 && val != 0 {
+			t.Fatalf("parseSize(%q) = %g without a number", s, val)
+		}
+		if math.IsNaN(val) {
+			t.Fatalf("parseSize(%q) = NaN", s)
+		}
+
+		// разобранное начало строки - корректный размер с тем же значением
+		if n > 0 {
+			got, err := ParseSize(s[:n])
+
+			if err != nil || got != val {
+				t.Fatalf("ParseSize(%q) = %g, %v, want %g", s[:n], got, err, val)
+			}
+		}
+
+		// то же число в KiB всегда больше
+		if n == 0 || val <= 0 || val > 1e12 {
+			return
+		}
+
+		plain := strconv.FormatFloat(val, 'f', -1, 64)
+		kib, err := ParseSize(plain + "KiB")
+
+		if err != nil || kib <= val {
+			t.Fatalf("ParseSize(%q) = %g, %v, want more than %g", plain+"KiB", kib, err, val)
+		}
+	})
+}
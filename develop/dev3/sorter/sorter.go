@@ -0,0 +1,116 @@
+package sorter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrDisorder - входные данные не отсортированы
+var ErrDisorder = errors.New("disorder")
+
+/*
+sorter - сортировка с заданными настройками
+opts Options - проверенные настройки
+cmp *Comparator - сравнение строк
+*/
+type sorter struct {
+	opts Options
+	cmp  *Comparator
+}
+
+func newSorter(opts Options) (*sorter, error) {
+	opts, err := opts.validate()
+
+	if err != nil {
+		return nil, err
+	}
+
+	cmp, err := NewComparator(opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &sorter{opts: opts, cmp: cmp}, nil
+}
+
+// Sort - сортирует строки из r и записывает результат в w, каждая строка завершается переводом строки
+func Sort(r io.Reader, w io.Writer, opts Options) error {
+	return SortAll([]io.Reader{r}, w, opts)
+}
+
+// SortAll - сортирует строки из всех inputs вместе и записывает результат в w
+func SortAll(inputs []io.Reader, w io.Writer, opts Options) error {
+	s, err := newSorter(opts)
+
+	if err != nil {
+		return err
+	}
+
+	return s.sortStream(inputs, w)
+}
+
+// Merge - сливает уже отсортированные inputs в w, не сортируя их
+func Merge(inputs []io.Reader, w io.Writer, opts Options) error {
+	s, err := newSorter(opts)
+
+	if err != nil {
+		return err
+	}
+
+	return s.mergeReaders(inputs, w, s.hasHeader())
+}
+
+/*
+Check - проверяет, что строки из r отсортированы тем же сравнением, что и при сортировке
+первая строка, нарушающая порядок, выводится в diag как "sort: name:N: disorder: строка", возвращается ErrDisorder
+с Options.Unique равные соседние строки тоже считаются нарушением порядка
+*/
+func Check(r io.Reader, name string, diag io.Writer, opts Options) error {
+	s, err := newSorter(opts)
+
+	if err != nil {
+		return err
+	}
+
+	return s.check(r, name, diag)
+}
+
+// check - метод проверки отсортированности строк из r, name - имя файла для сообщения
+func (s *sorter) check(r io.Reader, name string, diag io.Writer) error {
+	scanner := s.newScanner(r)
+	strict := s.opts.Unique
+
+	// номер первой строки данных, с учётом заголовка
+	first := 1
+
+	if s.hasHeader() {
+		if err := s.readHeader(scanner, true, &lineWriter{w: io.Discard}); err != nil {
+			return err
+		}
+		first = 2
+	}
+
+	var prev sortLine
+	for n := first; scanner.Scan(); n++ {
+		cur := s.cmp.prepare(scanner.Text())
+
+		if n > first {
+			c := s.cmp.compareLines(prev, cur)
+
+			if c > 0 || (strict && c == 0) {
+				fmt.Fprintf(diag, "sort: %s:%d: disorder: %s\n", name, n, cur.line)
+				return ErrDisorder
+			}
+		}
+
+		prev = cur
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("can not read file: %s", err.Error())
+	}
+
+	return nil
+}
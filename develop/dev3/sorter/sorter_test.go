@@ -0,0 +1,683 @@
+package sorter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+)
+
+// newTestSorter - сортировка в одной горутине, чтобы результат не зависел от числа процессоров
+func newTestSorter(opts Options) *sorter {
+	if opts.Parallel == 0 {
+		opts.Parallel = 1
+	}
+
+	s, err := newSorter(opts)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+func mustKeys(defs ...string) []KeyDef {
+	keys := make([]KeyDef, 0, len(defs))
+	for _, def := range defs {
+		key, err := ParseKeyDef(def)
+		if err != nil {
+			panic(err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func mustNamedKeys(defs ...string) []KeyDef {
+	keys, err := ParseKeys(defs, FormatCSV)
+	if err != nil {
+		panic(err)
+	}
+
+	return keys
+}
+
+func testLines() string {
+	rnd := rand.New(rand.NewSource(1))
+	words := []string{"apple", "pear", "plum", "fig", "kiwi", "lime"}
+	months := []string{"January", "March", "May", "July", "October", "December"}
+	sizes := []string{"1K", "20", "3M", "512", "2G", "7K"}
+
+	lines := make([]string, 0, 2000)
+	for i := 0; i < 2000; i++ {
+		lines = append(lines, fmt.Sprintf("%s %d %s %s",
+			words[rnd.Intn(len(words))],
+			rnd.Intn(50),
+			months[rnd.Intn(len(months))],
+			sizes[rnd.Intn(len(sizes))],
+		))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func TestExternalSortMatchesInMemory(t *testing.T) {
+	input := testLines()
+
+	tsts := []struct {
+		name string
+		opts Options
+	}{
+		{name: "default", opts: Options{}},
+		{name: "reverse", opts: Options{Reverse: true}},
+		{name: "unique", opts: Options{Unique: true}},
+		{name: "reverse unique", opts: Options{Reverse: true, Unique: true}},
+		{name: "numeric column", opts: Options{Keys: mustKeys("2,2"), Numeric: true}},
+		{name: "numeric column reverse unique", opts: Options{Keys: mustKeys("2,2"), Numeric: true, Reverse: true, Unique: true}},
+		{name: "months", opts: Options{Keys: mustKeys("3,3"), Months: true}},
+		{name: "human numbers", opts: Options{Keys: mustKeys("4,4"), Human: true, Reverse: true}},
+		{name: "several keys", opts: Options{Keys: mustKeys("2,2nr", "1.2,1.3")}},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			var want bytes.Buffer
+			if err := Sort(strings.NewReader(input), &want, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+
+			dir := t.TempDir()
+			opts := tt.opts
+			opts.BufferSize, opts.TempDir = 1<<10, dir
+
+			var got bytes.Buffer
+			if err := Sort(strings.NewReader(input), &got, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(got.Bytes(), want.Bytes()) {
+				t.Errorf("external sort output differs from in-memory sort")
+			}
+
+			left, _ := os.ReadDir(dir)
+			if len(left) != 0 {
+				t.Errorf("temporary files are not removed: %d", len(left))
+			}
+		})
+	}
+}
+
+func TestParseKeyDef(t *testing.T) {
+	tsts := []struct {
+		name    string
+		arg     string
+		want    KeyDef
+		wantErr bool
+	}{
+		{name: "field", arg: "2", want: KeyDef{StartField: 2, StartChar: 1}},
+		{name: "field range", arg: "2,3", want: KeyDef{StartField: 2, StartChar: 1, EndField: 3}},
+		{
+			name: "chars and modifiers",
+			arg:  "1.3b,1.5nr",
+			want: KeyDef{StartField: 1, StartChar: 3, EndField: 1, EndChar: 5, SkipStartBlanks: true, Numeric: true, Reverse: true},
+		},
+		{name: "end blanks", arg: "2,2b", want: KeyDef{StartField: 2, StartChar: 1, EndField: 2, SkipEndBlanks: true}},
+		{name: "random", arg: "3R", want: KeyDef{StartField: 3, StartChar: 1, Random: true}},
+		{name: "zero field", arg: "0,1", wantErr: true},
+		{name: "zero start char", arg: "1.0", wantErr: true},
+		{name: "unknown modifier", arg: "1x", wantErr: true},
+		{name: "no field", arg: ",2", wantErr: true},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseKeyDef(tt.arg)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseKeyDef(%q) error = %v, wantErr %v", tt.arg, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseKeyDef(%q) = %+v, want %+v", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+// ожидаемые результаты получены с помощью LC_ALL=C sort
+func TestSortKeys(t *testing.T) {
+	input := strings.Join([]string{
+		"b 10 March x:3:c",
+		"a 2 jan y:1:a",
+		"  c 2 May z:2:b",
+		"a 10 January w:1:b",
+		"B 3 feb v:3:a",
+		"d -1 December u:2:z",
+	}, "\n")
+
+	tsts := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{
+			name: "whole line",
+			opts: Options{},
+			want: []string{"  c 2 May z:2:b", "B 3 feb v:3:a", "a 10 January w:1:b", "a 2 jan y:1:a", "b 10 March x:3:c", "d -1 December u:2:z"},
+		},
+		{
+			name: "numeric key then reversed key",
+			opts: Options{Keys: mustKeys("2,2n", "1,1r")},
+			want: []string{"d -1 December u:2:z", "a 2 jan y:1:a", "  c 2 May z:2:b", "B 3 feb v:3:a", "b 10 March x:3:c", "a 10 January w:1:b"},
+		},
+		{
+			name: "character range",
+			opts: Options{Keys: mustKeys("1.2,1.3")},
+			want: []string{"d -1 December u:2:z", "a 10 January w:1:b", "b 10 March x:3:c", "a 2 jan y:1:a", "B 3 feb v:3:a", "  c 2 May z:2:b"},
+		},
+		{
+			name: "blanks skipped in key",
+			opts: Options{Keys: mustKeys("1b,1")},
+			want: []string{"B 3 feb v:3:a", "a 10 January w:1:b", "a 2 jan y:1:a", "b 10 March x:3:c", "  c 2 May z:2:b", "d -1 December u:2:z"},
+		},
+		{
+			name: "separator",
+			opts: Options{Separator: ":", Keys: mustKeys("2,2n", "3,3")},
+			want: []string{"a 2 jan y:1:a", "a 10 January w:1:b", "  c 2 May z:2:b", "d -1 December u:2:z", "B 3 feb v:3:a", "b 10 March x:3:c"},
+		},
+		{
+			name: "global options are inherited by keys without modifiers",
+			opts: Options{Numeric: true, Reverse: true, Keys: mustKeys("2,2")},
+			want: []string{"b 10 March x:3:c", "a 10 January w:1:b", "B 3 feb v:3:a", "a 2 jan y:1:a", "  c 2 May z:2:b", "d -1 December u:2:z"},
+		},
+		{
+			name: "fold case",
+			opts: Options{Keys: mustKeys("1b,1f")},
+			want: []string{"a 10 January w:1:b", "a 2 jan y:1:a", "B 3 feb v:3:a", "b 10 March x:3:c", "  c 2 May z:2:b", "d -1 December u:2:z"},
+		},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			var got bytes.Buffer
+			if err := Sort(strings.NewReader(input), &got, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+
+			if want := strings.Join(tt.want, "\n") + "\n"; got.String() != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got.String(), want)
+			}
+		})
+	}
+}
+
+func TestCompareModes(t *testing.T) {
+	tsts := []struct {
+		name  string
+		opts  Options
+		input []string
+		want  []string
+	}{
+		{
+			name:  "bytes",
+			opts:  Options{},
+			input: []string{"ёлка", "жук", "Арбуз", "елка", "арбуз"},
+			want:  []string{"Арбуз", "арбуз", "елка", "жук", "ёлка"},
+		},
+		{
+			name:  "russian locale",
+			opts:  Options{Locale: "ru_RU.UTF-8"},
+			input: []string{"ёлка", "жук", "Арбуз", "елка", "арбуз"},
+			want:  []string{"арбуз", "Арбуз", "елка", "ёлка", "жук"},
+		},
+		{
+			name:  "fold case",
+			opts:  Options{Fold: true},
+			input: []string{"b", "B", "a", "Яр", "ян"},
+			want:  []string{"a", "B", "b", "ян", "Яр"},
+		},
+		{
+			name:  "dictionary order",
+			opts:  Options{Dictionary: true},
+			input: []string{"a-c", "ab", "#z", "a.b"},
+			want:  []string{"a.b", "ab", "a-c", "#z"},
+		},
+		{
+			name:  "versions",
+			opts:  Options{Version: true},
+			input: []string{"v1.10", "v1.9", "v1.9a", "v1.9~rc1", "v1.2.3"},
+			want:  []string{"v1.2.3", "v1.9~rc1", "v1.9", "v1.9a", "v1.10"},
+		},
+		{
+			name:  "general numbers",
+			opts:  Options{General: true},
+			input: []string{"1e3", "-inf", "nan", "abc", "2.5", "0x10", "-3e-2"},
+			want:  []string{"abc", "nan", "-inf", "-3e-2", "2.5", "0x10", "1e3"},
+		},
+		{
+			name:  "month abbreviations",
+			opts:  Options{Months: true},
+			input: []string{"DEC", "feb", "Jan", "march", "foo", "AUGUST"},
+			want:  []string{"foo", "Jan", "feb", "march", "AUGUST", "DEC"},
+		},
+		{
+			name:  "russian months",
+			opts:  Options{Months: true},
+			input: []string{"мая", "Декабрь", "янв", "ФЕВРАЛЯ", "сентябрь"},
+			want:  []string{"янв", "ФЕВРАЛЯ", "мая", "сентябрь", "Декабрь"},
+		},
+		{
+			name:  "version key",
+			opts:  Options{Separator: "-", Keys: mustKeys("2V")},
+			input: []string{"app-1.10", "lib-1.9", "app-1.9"},
+			want:  []string{"app-1.9", "lib-1.9", "app-1.10"},
+		},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := append([]string(nil), tt.input...)
+			newTestSorter(tt.opts).sorting(lines)
+
+			if strings.Join(lines, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", lines, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tsts := []struct {
+		name     string
+		opts     Options
+		input    string
+		wantErr  error
+		wantDiag string
+	}{
+		{
+			name:  "sorted",
+			opts:  Options{},
+			input: "a\nb\nb\nc\n",
+		},
+		{
+			name:     "disorder",
+			opts:     Options{},
+			input:    "a\nc\nb\n",
+			wantErr:  ErrDisorder,
+			wantDiag: "sort: in.txt:3: disorder: b\n",
+		},
+		{
+			name:  "numeric key is used",
+			opts:  Options{Keys: mustKeys("2,2n")},
+			input: "x 2\ny 10\nz 100\n",
+		},
+		{
+			name:     "reverse months",
+			opts:     Options{Months: true, Reverse: true},
+			input:    "March\nFebruary\nMay\n",
+			wantErr:  ErrDisorder,
+			wantDiag: "sort: in.txt:3: disorder: May\n",
+		},
+		{
+			name:     "unique checks strict order",
+			opts:     Options{Unique: true},
+			input:    "a\nb\nb\n",
+			wantErr:  ErrDisorder,
+			wantDiag: "sort: in.txt:3: disorder: b\n",
+		},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			var diag bytes.Buffer
+			err := Check(strings.NewReader(tt.input), "in.txt", &diag, tt.opts)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Check() error = %v, want %v", err, tt.wantErr)
+			}
+			if diag.String() != tt.wantDiag {
+				t.Errorf("Check() diagnostics = %q, want %q", diag.String(), tt.wantDiag)
+			}
+		})
+	}
+}
+
+func TestStructuredFormats(t *testing.T) {
+	csvInput := "name,latency,note\n" +
+		"b,20,\"plain\"\n" +
+		"a,100,\"with, comma\"\n" +
+		"c,3,\"two\nlines\"\n"
+
+	tsts := []struct {
+		name  string
+		opts  Options
+		input string
+		want  string
+	}{
+		{
+			name:  "csv by column name",
+			opts:  Options{Format: "csv", Keys: mustNamedKeys("latency:n")},
+			input: csvInput,
+			want:  "name,latency,note\nc,3,\"two\nlines\"\nb,20,plain\na,100,\"with, comma\"\n",
+		},
+		{
+			name:  "csv by column number reversed with temporary files",
+			opts:  Options{Format: "csv", Keys: mustNamedKeys("1:r"), BufferSize: 1},
+			input: csvInput,
+			want:  "name,latency,note\nc,3,\"two\nlines\"\nb,20,plain\na,100,\"with, comma\"\n",
+		},
+		{
+			name:  "tsv",
+			opts:  Options{Format: "tsv", Keys: mustNamedKeys("size:h", "name")},
+			input: "name\tsize\nx\t2K\ny\t512\nz\t2K\n",
+			want:  "name\tsize\ny\t512\nx\t2K\nz\t2K\n",
+		},
+		{
+			name: "jsonl by nested path",
+			opts: Options{Format: "jsonl", Keys: mustNamedKeys(".req.latency:n", ".id")},
+			input: `{"id":"b","req":{"latency":1.5}}` + "\n" +
+				`{"id":"a","req":{"latency":12}}` + "\n" +
+				`{"id":"c"}` + "\n" +
+				`{"id":"a","req":{"latency":1.5}}` + "\n",
+			want: `{"id":"c"}` + "\n" +
+				`{"id":"a","req":{"latency":1.5}}` + "\n" +
+				`{"id":"b","req":{"latency":1.5}}` + "\n" +
+				`{"id":"a","req":{"latency":12}}` + "\n",
+		},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.TempDir = t.TempDir()
+
+			var got bytes.Buffer
+			if err := Sort(strings.NewReader(tt.input), &got, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+
+			if got.String() != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got.String(), tt.want)
+			}
+		})
+	}
+
+	err := Sort(strings.NewReader(csvInput), io.Discard, Options{Format: FormatCSV, Keys: mustNamedKeys("missing")})
+	if err == nil {
+		t.Error("expected error for unknown column")
+	}
+}
+
+func TestParseBufferSize(t *testing.T) {
+	tsts := []struct {
+		name    string
+		arg     string
+		want    int
+		wantErr bool
+	}{
+		{name: "empty", arg: "", want: 0},
+		{name: "kilobytes by default", arg: "10", want: 10 << 10},
+		{name: "bytes", arg: "100b", want: 100},
+		{name: "megabytes", arg: "2M", want: 2 << 20},
+		{name: "lower case suffix", arg: "1g", want: 1 << 30},
+		{name: "bad number", arg: "abc", wantErr: true},
+		{name: "unknown suffix", arg: "10%", wantErr: true},
+		{name: "too large", arg: "99999999999T", wantErr: true},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBufferSize(tt.arg)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseBufferSize(%q) error = %v, wantErr %v", tt.arg, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseBufferSize(%q) = %d, want %d", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParallelSortMatchesSequential(t *testing.T) {
+	lines := strings.Split(benchLines(100000), "\n")
+
+	for _, workers := range []int{2, 3, 4} {
+		t.Run(fmt.Sprintf("parallel %d", workers), func(t *testing.T) {
+			opts := Options{Keys: mustKeys("2,2n", "1,1"), Parallel: 1}
+
+			want := append([]string(nil), lines...)
+			newTestSorter(opts).sorting(want)
+
+			opts.Parallel = workers
+			got := append([]string(nil), lines...)
+			newTestSorter(opts).sorting(got)
+
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("line %d: got %q, want %q", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func benchLines(n int) string {
+	rnd := rand.New(rand.NewSource(2))
+	lines := make([]string, 0, n)
+
+	for i := 0; i < n; i++ {
+		lines = append(lines, fmt.Sprintf("user%d %d %x", rnd.Intn(1000), rnd.Intn(100000), rnd.Int63()))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func BenchmarkSort(b *testing.B) {
+	lines := strings.Split(benchLines(2000000), "\n")
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("parallel=%d", workers), func(b *testing.B) {
+			fs := newTestSorter(Options{Keys: mustKeys("2,2n", "1,1"), Parallel: workers})
+			buf := make([]string, len(lines))
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				copy(buf, lines)
+				fs.sorting(buf)
+			}
+		})
+	}
+}
+
+func TestRandomOrder(t *testing.T) {
+	input := strings.Split(testLines(), "\n")
+	opts := Options{Keys: mustKeys("1,1R"), Seed: 42}
+
+	sortWith := func(opts Options) []string {
+		lines := append([]string(nil), input...)
+		newTestSorter(opts).sorting(lines)
+		return lines
+	}
+
+	got := sortWith(opts)
+
+	if again := sortWith(opts); strings.Join(again, "\n") != strings.Join(got, "\n") {
+		t.Error("the same seed gives a different order")
+	}
+
+	// строки с одинаковым ключом идут подряд, каждый ключ встречается одной группой
+	groups := make(map[string]bool)
+	for i, line := range got {
+		word, _, _ := strings.Cut(line, " ")
+
+		if i > 0 && strings.HasPrefix(got[i-1], word+" ") {
+			continue
+		}
+		if groups[word] {
+			t.Fatalf("key %q is not grouped", word)
+		}
+		groups[word] = true
+	}
+
+	orders := make(map[string]bool)
+	for seed := uint64(1); seed <= 10; seed++ {
+		lines := sortWith(Options{Random: true, Seed: seed})
+		orders[strings.Join(lines, "\n")] = true
+
+		sorted := append([]string(nil), lines...)
+		newTestSorter(Options{}).sorting(sorted)
+		if strings.Join(sorted, "\n") != strings.Join(sortWith(Options{}), "\n") {
+			t.Fatalf("seed %d: lines are lost or duplicated", seed)
+		}
+	}
+
+	if len(orders) < 2 {
+		t.Error("different seeds give the same order")
+	}
+}
+
+func TestHead(t *testing.T) {
+	input := testLines()
+
+	tsts := []struct {
+		name string
+		head int
+		opts Options
+	}{
+		{name: "whole line", head: 10},
+		{name: "largest numbers", head: 5, opts: Options{Keys: mustKeys("2,2n"), Reverse: true}},
+		{name: "stable equal keys", head: 25, opts: Options{Keys: mustKeys("3,3M"), Stable: true}},
+		{name: "unique", head: 30, opts: Options{Keys: mustKeys("1,1", "2,2n"), Unique: true}},
+		{name: "more than input", head: 5000, opts: Options{Keys: mustKeys("4,4h")}},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			var full bytes.Buffer
+			if err := Sort(strings.NewReader(input), &full, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+
+			want := strings.SplitAfter(full.String(), "\n")
+			want = want[:min(tt.head, len(want)-1)]
+
+			opts := tt.opts
+			opts.Head = tt.head
+
+			var got bytes.Buffer
+			if err := Sort(strings.NewReader(input), &got, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			if got.String() != strings.Join(want, "") {
+				t.Errorf("got:\n%s\nwant:\n%s", got.String(), strings.Join(want, ""))
+			}
+		})
+	}
+}
+
+// ожидаемые результаты получены с помощью LC_ALL=C sort | uniq -c и uniq -d
+func TestGroups(t *testing.T) {
+	input := "b 2\na 1\nB 2\nc 3\na 1\nb 2\n"
+
+	tsts := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "count",
+			opts: Options{Count: true},
+			want: "      1 B 2\n      2 a 1\n      2 b 2\n      1 c 3\n",
+		},
+		{
+			name: "count by key",
+			opts: Options{Count: true, Keys: mustKeys("2,2n")},
+			want: "      2 a 1\n      3 b 2\n      1 c 3\n",
+		},
+		{
+			name: "repeated",
+			opts: Options{Repeated: true, Fold: true},
+			want: "a 1\nb 2\n",
+		},
+		{
+			name: "repeated with count",
+			opts: Options{Repeated: true, Count: true, Fold: true},
+			want: "      2 a 1\n      3 b 2\n",
+		},
+		{
+			name: "count with temporary files",
+			opts: Options{Count: true, Keys: mustKeys("1,1f"), BufferSize: 1},
+			want: "      2 a 1\n      3 b 2\n      1 c 3\n",
+		},
+		{
+			name: "count head",
+			opts: Options{Count: true, Keys: mustKeys("2,2nr"), Head: 2},
+			want: "      1 c 3\n      3 b 2\n",
+		},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.TempDir = t.TempDir()
+
+			var got bytes.Buffer
+			if err := Sort(strings.NewReader(input), &got, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+
+			if got.String() != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestDates(t *testing.T) {
+	tsts := []struct {
+		name  string
+		opts  Options
+		input []string
+		want  []string
+	}{
+		{
+			name:  "rfc3339 with zones",
+			opts:  Options{Date: "RFC3339", Keys: mustKeys("2,2")},
+			input: []string{"b 2024-03-01T10:00:00+03:00", "a 2024-03-01T08:00:00Z", "d 2023-12-31T23:59:59Z"},
+			want:  []string{"d 2023-12-31T23:59:59Z", "b 2024-03-01T10:00:00+03:00", "a 2024-03-01T08:00:00Z"},
+		},
+		{
+			name:  "custom layout over two fields",
+			opts:  Options{Date: "02.01.2006 15:04", Keys: mustKeys("1,2")},
+			input: []string{"01.02.2024 09:30 x", "31.01.2024 23:00 y", "01.02.2024 08:15 z"},
+			want:  []string{"31.01.2024 23:00 y", "01.02.2024 08:15 z", "01.02.2024 09:30 x"},
+		},
+		{
+			name:  "not parsed go first",
+			opts:  Options{Date: "DateOnly", Separator: ",", Keys: mustKeys("2,2")},
+			input: []string{"a,2024-05-01", "b,soon", "c,2021-01-01", "d,"},
+			want:  []string{"b,soon", "d,", "c,2021-01-01", "a,2024-05-01"},
+		},
+		{
+			name:  "reverse",
+			opts:  Options{Date: "DateOnly", Reverse: true},
+			input: []string{"2024-05-01", "2021-01-01", "2023-07-15"},
+			want:  []string{"2024-05-01", "2023-07-15", "2021-01-01"},
+		},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := append([]string(nil), tt.input...)
+			newTestSorter(tt.opts).sorting(lines)
+
+			if strings.Join(lines, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got %q, want %q", lines, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package sorter
+
+import "fmt"
+
+/*
+groupWriter - вывод отсортированных строк с учётом -u, --count, --repeated и Options.Head
+строки с равными ключами идут подряд, от группы выводится первая строка,
+поэтому строка группы пишется, только когда приходит строка с другими ключами или вызывается flush
+lw *lineWriter - место записи
+cmp *Comparator - сравнение строк, при группировке без сравнения строк целиком
+opts Options - настройки группировки и ограничение числа строк
+first sortLine - первая строка текущей группы
+n int - число строк в текущей группе, 0 - группы нет
+written int - число выведенных строк
+*/
+type groupWriter struct {
+	lw      *lineWriter
+	cmp     *Comparator
+	opts    Options
+	first   sortLine
+	n       int
+	written int
+}
+
+func newGroupWriter(lw *lineWriter, cmp *Comparator, opts Options) *groupWriter {
+	return &groupWriter{lw: lw, cmp: cmp, opts: opts}
+}
+
+// add - метод добавляющий очередную строку, count - сколько строк она заменяет
+func (g *groupWriter) add(item sortLine, count int) error {
+	if !g.opts.grouped() {
+		return g.write(item.line, count)
+	}
+
+	if g.n > 0 && g.cmp.compareLines(g.first, item) == 0 {
+		g.n += count
+		return nil
+	}
+
+	if err := g.flush(); err != nil {
+		return err
+	}
+
+	g.first, g.n = item, count
+
+	return nil
+}
+
+// flush - метод выводящий текущую группу
+func (g *groupWriter) flush() error {
+	if g.n == 0 {
+		return nil
+	}
+
+	n := g.n
+	g.n = 0
+
+	if g.opts.Repeated && n < 2 {
+		return nil
+	}
+
+	return g.write(g.first.line, n)
+}
+
+// write - метод записывающий строку, с --count перед строкой пишется число повторов как в uniq -c
+func (g *groupWriter) write(line string, count int) error {
+	if g.done() {
+		return nil
+	}
+
+	if g.opts.Count {
+		line = fmt.Sprintf("%7d %s", count, line)
+	}
+
+	g.written++
+
+	return g.lw.WriteLine(line)
+}
+
+// done - выведено Options.Head строк, остальные строки не нужны
+func (g *groupWriter) done() bool {
+	return g.opts.Head > 0 && g.written >= g.opts.Head
+}
@@ -2,372 +2,277 @@ package main
 
 import (
 	"bufio"
+	"dev3/sorter"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
 	"os"
-	"sort"
-	"strconv"
 	"strings"
-	"time"
 )
 
-var suffixes = map[string]float64{
-	"K": 1e3,
-	"M": 1e6,
-	"G": 1e9,
-	"T": 1e12,
-	"P": 1e15,
-	"E": 1e18,
-	"Z": 1e21,
-	"Y": 1e24,
-}
-
 /*
-ConsoleArgs - структура хранящая доступные флаги
-FlgColl map[string]interface{} - мапа хранаящая имя флага: значение
+ConsoleArgs - структура хранящая настройки из флагов командной строки
+Opts sorter.Options - настройки сортировки
+Output string - файл для результата -o, пустой - стандартный вывод
+Merge bool - слить уже отсортированные файлы -m
+Check bool - проверить отсортированность -c или -C
+Quiet bool - не выводить строку, нарушающую порядок, -C
 */
 type ConsoleArgs struct {
-	FlgColl map[string]interface{}
+	Opts   sorter.Options
+	Output string
+	Merge  bool
+	Check  bool
+	Quiet  bool
 }
 
 // ParseFlags - парсим флаги из консоли
 func (ca *ConsoleArgs) ParseFlags() {
-	k := flag.Int("k", 0, "sort via a key; KEYDEF gives location and type")
+	var keys keysFlag
+	flag.Var(&keys, "k", "sort via a key; KEYDEF gives location and type: F[.C][OPTS][,F[.C][OPTS]], "+
+		"OPTS is one or more of bdfghMnRrV, may be repeated; with --format key is NAME[:OPTS]")
+	t := flag.String("t", "", "use SEP instead of non-blank to blank transition")
 	n := flag.Bool("n", false, "compare according to string numerical value")
 	r := flag.Bool("r", false, "reverse the result of comparisons")
 	u := flag.Bool("u", false, "with -c, check for strict ordering; without "+
 		"-c, output only the first of an equal run")
-	m := flag.Bool("M", false, "compare (unknown) < 'JAN' < ... < 'DEC'")
+	count := flag.Bool("count", false, "like uniq -c, output the first of an equal run prefixed by the number of lines in it")
+	repeated := flag.Bool("repeated", false, "output only the first of an equal run with more than one line")
+	m := flag.Bool("M", false, "compare (unknown) < 'JAN' < ... < 'DEC', month names in any case, "+
+		"abbreviated or full, English or Russian")
+	date := flag.String("date", "", "compare keys without n, h, M, g, V, R as timestamps in LAYOUT, a Go time layout "+
+		"(e.g., '2006-01-02 15:04') or a name such as RFC3339, DateTime, DateOnly")
 	b := flag.Bool("b", false, "ignore leading blanks")
 	c := flag.Bool("c", false, "check for sorted input; do not sort")
-	h := flag.Bool("h", false, "compare human readable numbers (e.g., 2K 1G)")
-
-	flag.Parse()
-
-	ca.FlgColl = make(map[string]interface{}, 8)
-	ca.FlgColl["-k"] = *k
-	ca.FlgColl["-n"] = *n
-	ca.FlgColl["-r"] = *r
-	ca.FlgColl["-u"] = *u
-	ca.FlgColl["-M"] = *m
-	ca.FlgColl["-b"] = *b
-	ca.FlgColl["-c"] = *c
-	ca.FlgColl["-h"] = *h
-}
-
-// ReadFilePaths - последними аргументами ожидаем передачи пути до файлов, в которых нужно отсортировать данные
-func (ca *ConsoleArgs) ReadFilePaths() []string {
-	args := os.Args
-	filesPath := make([]string, 0)
-
-	for i := len(args) - 1; i >= 1; i-- {
-		_, ok := ca.FlgColl[args[i]]
-
-		if ok || (!strings.Contains(args[i], "\"") && !strings.Contains(args[i], "/")) {
-			break
-		}
-		filesPath = append(filesPath, args[i])
+	C := flag.Bool("C", false, "like -c, but do not report first bad line")
+	h := flag.Bool("h", false, "compare human readable numbers (e.g., 2K 1.5G 512Ki 20MiB)")
+	f := flag.Bool("f", false, "fold lower case to upper case characters")
+	d := flag.Bool("d", false, "consider only blanks and alphanumeric characters")
+	g := flag.Bool("g", false, "compare according to general numerical value")
+	V := flag.Bool("V", false, "natural sort of (version) numbers within text")
+	R := flag.Bool("R", false, "shuffle, but group identical keys")
+	seed := flag.Uint64("random-seed", 0, "seed for -R, the same seed gives the same order; 0 picks a random seed")
+	s := flag.Bool("s", false, "stabilize sort by disabling last-resort comparison")
+	head := flag.Int("head", 0, "output only the first K lines of the sorted result, without sorting all input")
+	locale := flag.String("locale", "", "compare text according to collation rules of LOCALE (e.g., ru_RU.UTF-8), "+
+		"C or empty compares bytes")
+	S := flag.String("S", "", "use SIZE for main memory buffer (e.g., 512K 100M 1G), "+
+		"larger inputs are sorted with temporary files; without suffix SIZE is in KiB")
+	T := flag.String("T", "", "use DIR for temporaries, not $TMPDIR or /tmp")
+	o := flag.String("o", "", "write result to FILE instead of standard output, FILE may be one of the inputs")
+	merge := flag.Bool("m", false, "merge already sorted files; do not sort")
+	format := flag.String("format", "", "read and write records as csv, tsv (both with a header row) or jsonl")
+	parallel := flag.Int("parallel", sorter.DefaultParallel(), "change the number of sorts run concurrently to N")
+
+	// значения можно писать слитно с флагом, как в GNU sort: -k2,2n -t:
+	if err := flag.CommandLine.Parse(splitShortArgs(os.Args[1:], "ktSTo")); err != nil {
+		fatal(err)
 	}
-	return filesPath
-}
 
-/*
-FileSorter - структура занимающаяся сортировкой файла
-Flags *ConsoleArgs - указатель на структуру флагов
-filesPath []string - пути до файлов
-*/
-type FileSorter struct {
-	Flags     *ConsoleArgs
-	filesPath []string
-}
+	keyDefs, errKeys := sorter.ParseKeys(keys, *format)
 
-func NewFileSorter(flgs *ConsoleArgs) *FileSorter {
-	return &FileSorter{
-		Flags:     flgs,
-		filesPath: make([]string, 0),
+	if errKeys != nil {
+		fatal(errKeys)
 	}
-}
 
-// SetFileNames - метод для инициализации путей к файлам
-func (fs *FileSorter) SetFileNames(flsPth []string) {
-	fs.filesPath = flsPth
-}
+	size, errSize := sorter.ParseBufferSize(*S)
 
-/*
-ReadFile - метод читающий строки из файла
-filePath string - путь до файла
-*/
-func (fs *FileSorter) ReadFile(filePath string) ([]string, error) {
-	file, errOpen := os.Open(filePath)
-	defer func(file *os.File) {
-		err := file.Close()
-		if err != nil {
-			log.Fatalf("Can not close file: %s", err.Error())
-		}
-	}(file)
-	strValues := make([]string, 0)
-
-	if errOpen != nil {
-		return nil, fmt.Errorf("file not exist: %s", errOpen.Error())
+	if errSize != nil {
+		fatal(errSize)
 	}
 
-	scanner := bufio.NewScanner(file)
-	scanner.Split(bufio.ScanLines)
-	success := scanner.Scan()
-	for success {
-		if !success {
-			err := scanner.Err()
-
-			if err != nil {
-				return nil, fmt.Errorf("can not read file: %s", err.Error())
-			}
-			break
-		}
+	if *parallel < 1 {
+		fatal(fmt.Errorf("invalid number of parallel sorts %d", *parallel))
+	}
 
-		strValues = append(strValues, scanner.Text())
-		success = scanner.Scan()
+	if *head < 0 {
+		fatal(fmt.Errorf("invalid number of lines %d", *head))
 	}
 
-	return strValues, nil
+	ca.Opts = sorter.Options{
+		Keys:         keyDefs,
+		Separator:    *t,
+		IgnoreBlanks: *b,
+		Numeric:      *n,
+		Human:        *h,
+		Months:       *m,
+		Fold:         *f,
+		Dictionary:   *d,
+		General:      *g,
+		Version:      *V,
+		Random:       *R,
+		Reverse:      *r,
+		Stable:       *s,
+		Unique:       *u,
+		Count:        *count,
+		Repeated:     *repeated,
+		Seed:         *seed,
+		Head:         *head,
+		Date:         *date,
+		Locale:       *locale,
+		Format:       *format,
+		BufferSize:   size,
+		TempDir:      *T,
+		Parallel:     *parallel,
+	}
+	ca.Output = *o
+	ca.Merge = *merge
+	ca.Check = *c || *C
+	ca.Quiet = *C
+
+	// неверный разделитель, формат или локаль - ошибка до открытия файлов
+	if _, err := sorter.NewComparator(ca.Opts); err != nil {
+		fatal(err)
+	}
 }
 
-// Sort - метод для сортировки файла
-func (fs *FileSorter) Sort() error {
-	for _, path := range fs.filesPath {
-		strVl, errRF := fs.ReadFile(path)
-		if errRF != nil {
-			return errRF
-		}
-
-		// проверка на отсортированость файла, если установлен соответствующий флаг
-		if fs.Flags.FlgColl["-c"].(bool) {
-			item := fs.isSorted(strVl)
-
-			if item != "" {
-				fmt.Printf("disorder: %s\n", item)
-			}
-			return nil
-		}
-
-		// сортируем файл
-		fs.sorting(strVl)
+// keysFlag - значения флага -k, флаг можно указывать несколько раз, разбираются после всех флагов с учётом --format
+type keysFlag []string
 
-		// если установлен флаг разворачиваем результат
-		if fs.Flags.FlgColl["-r"].(bool) {
-			reverse(strVl)
-		}
+func (kf *keysFlag) String() string {
+	return strings.Join(*kf, " ")
+}
 
-		// если установлен флаг, оставляем только уникальные значения
-		if fs.Flags.FlgColl["-u"].(bool) {
-			strVl = unique(strVl)
-		}
+func (kf *keysFlag) Set(s string) error {
+	*kf = append(*kf, s)
 
-		// превращаем массив строк в единую строку
-		strRes := fs.stringRes(strVl)
+	return nil
+}
 
-		// записываем результат в файл
-		errWrToFile := fs.writeToFile(strRes, path)
+// splitShortArgs - разделяет слитно записанные флаг и значение (-k2,2n -> -k 2,2n) для флагов из names
+func splitShortArgs(args []string, names string) []string {
+	res := make([]string, 0, len(args))
 
-		if errWrToFile != nil {
-			return errWrToFile
+	for i, arg := range args {
+		if arg == "--" {
+			return append(res, args[i:]...)
 		}
-	}
-	return nil
-}
 
-/*
-writeToFile - метод для записи результата в файл
-str string - результат, который нужно записать в файл
-filePath string - путь до текущего файла
-*/
-func (fs *FileSorter) writeToFile(str string, filePath string) error {
-	partsPath := strings.Split(filePath, "/")
-	//создаём новый файл с приставкой _res
-	nameFile := partsPath[len(partsPath)-1] + "_res"
-	file, errOpen := os.OpenFile(
-		nameFile,
-		os.O_WRONLY|os.O_TRUNC|os.O_CREATE,
-		0666,
-	)
-
-	defer func(file *os.File) {
-		if err := file.Close(); err != nil {
-			log.Fatalf("cat not close file: %s", err.Error())
+		if len(arg) > 2 && arg[0] == '-' && strings.IndexByte(names, arg[1]) >= 0 && arg[2] != '=' {
+			res = append(res, arg[:2], arg[2:])
+			continue
 		}
-	}(file)
 
-	if errOpen != nil {
-		return fmt.Errorf("can not open file: %s", errOpen.Error())
+		res = append(res, arg)
 	}
 
-	_, errWrite := file.Write([]byte(str))
+	return res
+}
+
+// ReadFilePaths - аргументы после флагов считаются путями до файлов, без файлов или с "-" читаем стандартный ввод
+func (ca *ConsoleArgs) ReadFilePaths() []string {
+	filesPath := flag.Args()
 
-	if errWrite != nil {
-		return fmt.Errorf("can not write to file: %s", errWrite.Error())
+	if len(filesPath) == 0 {
+		return []string{"-"}
 	}
 
-	return nil
+	return filesPath
 }
 
 /*
-stringRes - метод для превразения массива строк в строку
-res []string - массив строк
+FileSorter - структура занимающаяся сортировкой файлов, сравнение и сортировка строк в пакете sorter
+Flags *ConsoleArgs - указатель на структуру флагов
+filesPath []string - пути до файлов
 */
-func (fs *FileSorter) stringRes(res []string) string {
-	var strRes strings.Builder
-	resSlice := res
-
-	for i := 0; i < len(resSlice); i++ {
-		strRes.WriteString(resSlice[i])
-		if i < len(resSlice)-1 {
-			strRes.WriteString("\n")
-		}
+type FileSorter struct {
+	Flags     *ConsoleArgs
+	filesPath []string
+}
+
+func NewFileSorter(flgs *ConsoleArgs) *FileSorter {
+	return &FileSorter{
+		Flags:     flgs,
+		filesPath: make([]string, 0),
 	}
+}
 
-	return strRes.String()
+// SetFileNames - метод для инициализации путей к файлам
+func (fs *FileSorter) SetFileNames(flsPth []string) {
+	fs.filesPath = flsPth
 }
 
-/*
-sorting - метод для сортировки строк
-*/
-func (fs *FileSorter) sorting(lines []string) {
-	sort.SliceStable(lines, func(i, j int) bool {
-		a := strings.Fields(lines[i])
-		b := strings.Fields(lines[j])
-
-		// если указан флаг в сравниваем с учётом суффикса
-		if fs.Flags.FlgColl["-h"].(bool) {
-			return fs.compareNumericSuffix(a, b)
-		}
+// Sort - метод сортирующий все входные файлы вместе, результат пишется в стандартный вывод или в файл -o
+func (fs *FileSorter) Sort() error {
+	// проверка на отсортированость файла, если установлен соответствующий флаг
+	if fs.Flags.Check {
+		return fs.Check()
+	}
 
-		// если установлен флаг сравниваем месяцы
-		if fs.Flags.FlgColl["-M"].(bool) {
-			return fs.compareMonths(a, b)
+	inputs := make([]io.Reader, 0, len(fs.filesPath))
+	for _, path := range fs.filesPath {
+		if path == "-" {
+			inputs = append(inputs, os.Stdin)
+			continue
 		}
 
-		// иначе сравниваем строки как есть
-		return fs.compareLines(a, b)
-	})
-}
+		file, errOpen := os.Open(path)
 
-// compareMonths - метод для сравнения месяцев
-func (fs *FileSorter) compareMonths(a, b []string) bool {
-	column := fs.columnNumber()
+		if errOpen != nil {
+			return fmt.Errorf("file not exist: %s", errOpen.Error())
+		}
+		defer file.Close()
 
-	if column > len(a)-1 || column > len(b)-1 {
-		column = 0
+		inputs = append(inputs, file)
 	}
-	formatDate := "2006-January-02"
 
-	// приводим значения к дате
-	dateA, errA := time.Parse(formatDate, fmt.Sprintf("2006-%s-01", a[column]))
-	dateB, errB := time.Parse(formatDate, fmt.Sprintf("2006-%s-01", b[column]))
+	out, errOut := openOutput(fs.Flags.Output)
 
-	if errA != nil || errB != nil {
-		fmt.Println("incorrect month name")
-		return false
+	if errOut != nil {
+		return errOut
 	}
 
-	return dateA.Before(dateB)
-}
+	bw := bufio.NewWriter(out)
 
-// compareLines - метод для сравнения строк
-func (fs *FileSorter) compareLines(a, b []string) bool {
-	column := fs.columnNumber()
-
-	if column > len(a)-1 || column > len(b)-1 {
-		column = 0
+	var errSort error
+	if fs.Flags.Merge {
+		errSort = sorter.Merge(inputs, bw, fs.Flags.Opts)
+	} else {
+		errSort = sorter.SortAll(inputs, bw, fs.Flags.Opts)
 	}
 
-	// если установлен флаг, игнорируем хвостовые пробелы
-	if fs.Flags.FlgColl["-b"].(bool) {
-		a[column] = strings.TrimRight(a[column], " ")
-		b[column] = strings.TrimRight(b[column], " ")
+	if errSort == nil {
+		errSort = bw.Flush()
 	}
 
-	// если установлен флаг, сравниваем строку как число
-	if fs.Flags.FlgColl["-n"].(bool) {
-		numA, errA := strconv.Atoi(a[column])
-		numB, errB := strconv.Atoi(b[column])
-
-		if errA == nil && errB == nil {
-			return numA < numB
-		}
-	}
-
-	// иначе сравниваем символы определёной колонки как строки
-	return a[column] < b[column]
+	return out.Close(errSort)
 }
 
-// compareNumericSuffix - метод для сравнения символов с суффиксом
-func (fs *FileSorter) compareNumericSuffix(a, b []string) bool {
-	column := fs.columnNumber()
+/*
+Check - метод проверяющий отсортированность входного файла тем же сравнением, что и при сортировке
+с -c первая строка, нарушающая порядок, выводится в stderr, с -C ничего не выводится
+с -u равные соседние строки тоже считаются нарушением порядка
+*/
+func (fs *FileSorter) Check() error {
+	path := "-"
 
-	if column > len(a)-1 || column > len(b)-1 {
-		column = 0
+	if len(fs.filesPath) > 1 {
+		return fmt.Errorf("extra operand %q not allowed with -c", fs.filesPath[1])
 	}
-
-	// приводим числа с суфиксами к числу
-	numA, errA := fs.numericSuffix(a[column])
-	numB, errB := fs.numericSuffix(b[column])
-
-	// ошибки при конвертации нет, сравниваем полученные числа
-	if errA == nil && errB == nil {
-		return numA < numB
+	if len(fs.filesPath) == 1 {
+		path = fs.filesPath[0]
 	}
 
-	// иначе просто сравниваем как строки
-	return a[column] < b[column]
-}
-
-// numericSuffix - метод приводящий число с суффиксом к int64
-func (fs *FileSorter) numericSuffix(s string) (int64, error) {
-	for suf, mul := range suffixes {
-		if strings.HasSuffix(s, suf) {
-			strSuf := strings.TrimSuffix(s, suf)
-			val, err := strconv.ParseInt(strSuf, 10, 64)
+	var r io.Reader = os.Stdin
 
-			if err != nil {
-				return 0, err
-			}
+	if path != "-" {
+		file, errOpen := os.Open(path)
 
-			return int64(mul) * val, nil
+		if errOpen != nil {
+			return fmt.Errorf("file not exist: %s", errOpen.Error())
 		}
-	}
-
-	return strconv.ParseInt(s, 10, 64)
-}
+		defer file.Close()
 
-// columnNumber - метод для получения номера колонки
-func (fs *FileSorter) columnNumber() int {
-	if fs.Flags.FlgColl["-k"].(int) != 0 {
-		return fs.Flags.FlgColl["-k"].(int) - 1
+		r = file
 	}
 
-	return 0
-}
-
-// isSorted - метод для поверки сотсортированности файла
-func (fs *FileSorter) isSorted(lines []string) string {
-	var cur string
-
-	for i := 0; i < len(lines); i++ {
-		items := strings.Fields(lines[i])
-		for j := 0; j < len(items); j++ {
-			if i == 0 && j == 0 {
-				cur = items[j]
-				continue
-			}
-			if items[j] < cur && !fs.Flags.FlgColl["-r"].(bool) {
-				return items[j]
-			} else if items[j] > cur && fs.Flags.FlgColl["-r"].(bool) {
-				return items[j]
-			}
-		}
+	var diag io.Writer = os.Stderr
+	if fs.Flags.Quiet {
+		diag = io.Discard
 	}
 
-	return ""
+	return sorter.Check(r, path, diag, fs.Flags.Opts)
 }
 
 func main() {
@@ -377,31 +282,18 @@ func main() {
 	fileSorter.SetFileNames(clsArgs.ReadFilePaths())
 	err := fileSorter.Sort()
 
-	if err != nil {
-		fmt.Println(err)
+	// коды завершения как у GNU sort: 1 - файл не отсортирован (-c, -C), 2 - ошибка
+	if errors.Is(err, sorter.ErrDisorder) {
+		os.Exit(1)
 	}
-}
 
-func reverse(strs []string) {
-	for i, j := 0, len(strs)-1; i < j; i, j = i+1, j-1 {
-		strs[i], strs[j] = strs[j], strs[i]
-	}
-}
-
-func unique(strs []string) []string {
-	keys := make(map[string]bool, len(strs))
-	res := make([]string, 0)
-
-	for _, entry := range strs {
-		if _, ok := keys[entry]; !ok {
-			keys[entry] = true
-			res = append(res, entry)
-		}
+	if err != nil {
+		fatal(err)
 	}
-
-	return res
 }
 
-func isNumeric(c byte) bool {
-	return c >= '0' && c <= '9'
+// fatal - выводит ошибку в стандартный поток ошибок без времени и завершает программу с кодом 2, как GNU sort
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "sort: %s\n", err.Error())
+	os.Exit(2)
 }
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"dev3/sorter"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSortFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+
+	tsts := []struct {
+		name   string
+		first  string
+		second string
+		args   ConsoleArgs
+		want   string
+	}{
+		{
+			name:   "files are sorted together in place",
+			first:  "pear\napple",
+			second: "plum\nfig\n",
+			args:   ConsoleArgs{Output: first},
+			want:   "apple\nfig\npear\nplum\n",
+		},
+		{
+			name:   "merge sorted files",
+			first:  "1\n3\n5\n",
+			second: "2\n3\n4",
+			args:   ConsoleArgs{Output: first, Merge: true, Opts: sorter.Options{Numeric: true, Unique: true}},
+			want:   "1\n2\n3\n4\n5\n",
+		},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(first, []byte(tt.first), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(second, []byte(tt.second), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			fs := NewFileSorter(&tt.args)
+			fs.SetFileNames([]string{first, second})
+
+			if err := fs.Sort(); err != nil {
+				t.Fatal(err)
+			}
+
+			got, _ := os.ReadFile(first)
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+
+			entries, _ := os.ReadDir(dir)
+			if len(entries) != 2 {
+				t.Errorf("temporary output file is not removed: %d files in dir", len(entries))
+			}
+		})
+	}
+}
+
+// TestExitCodes - коды завершения как у GNU sort, программа запускается заново тем же тестовым файлом
+func TestExitCodes(t *testing.T) {
+	if args, ok := os.LookupEnv("SORT_TEST_ARGS"); ok {
+		os.Args = append([]string{"sort"}, strings.Fields(args)...)
+		main()
+		os.Exit(0)
+	}
+
+	tsts := []struct {
+		name  string
+		args  string
+		input string
+		want  int
+	}{
+		{name: "sorted", args: "-c", input: "a\nb\n", want: 0},
+		{name: "disorder", args: "-c", input: "b\na\n", want: 1},
+		{name: "bad key", args: "-k 0", input: "a\n", want: 2},
+		{name: "bad buffer size", args: "-S x", input: "a\n", want: 2},
+		{name: "bad number of parallel sorts", args: "--parallel 0", input: "a\n", want: 2},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command(os.Args[0], "-test.run=^TestExitCodes$")
+			cmd.Env = append(os.Environ(), "SORT_TEST_ARGS="+tt.args)
+			cmd.Stdin = strings.NewReader(tt.input)
+
+			var stderr strings.Builder
+			cmd.Stderr = &stderr
+
+			err := cmd.Run()
+			code := 0
+
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				code = exitErr.ExitCode()
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if code != tt.want {
+				t.Errorf("exit code = %d, want %d, stderr %q", code, tt.want, stderr.String())
+			}
+			if tt.want == 2 && !strings.HasPrefix(stderr.String(), "sort: ") {
+				t.Errorf("stderr = %q, want message starting with \"sort: \"", stderr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+)
+
+// значения --binary-files, как в GNU grep
+const (
+	// binaryMatches - вместо строк двоичного файла выводится только сообщение о совпадении
+	binaryMatches = "binary"
+	// binaryText - двоичный файл ищется как текстовый
+	binaryText = "text"
+	// binaryWithoutMatch - в двоичном файле нет совпадений
+	binaryWithoutMatch = "without-match"
+)
+
+// binaryBlock - размер первого блока файла, по которому определяется, двоичный ли он
+const binaryBlock = 32 << 10
+
+/*
+isBinary - двоичный ли файл: в первом блоке есть нулевой байт, которого не бывает в тексте
+блок читается без продвижения r, ошибка чтения вернётся при следующем чтении
+*/
+func isBinary(r *bufio.Reader) bool {
+	head, _ := r.Peek(binaryBlock)
+
+	return bytes.IndexByte(head, 0) >= 0
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"regexp/syntax"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+boundaryVM - поиск выражения с границами слова \<, \>, \b и \B, которых нет в RE2
+выражение компилируется тем же пакетом regexp/syntax, а выполняется здесь: метки границ - пустые группы,
+и поток, проходящий метку с неверной границей, отбрасывается, поэтому, если самое длинное совпадение
+не подходит, находится более короткое или следующее, как в GNU grep
+поиск - машина Пайка, как в regexp: все потоки идут по строке одновременно, время линейно от длины строки
+prog *syntax.Prog - программа выражения
+marks map[int]string - вид метки по номеру ячейки начала её группы
+ncap int - число отслеживаемых ячеек групп: совпадение целиком и первая группа для -w
+*/
+type boundaryVM struct {
+	prog  *syntax.Prog
+	marks map[int]string
+	ncap  int
+}
+
+// newBoundaryVM - компилирует выражение в синтаксисе Go, без меток границ возвращает nil
+func newBoundaryVM(expr string) (*boundaryVM, error) {
+	re, err := syntax.Parse(expr, syntax.Perl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	marks := make(map[int]string)
+	collectMarks(re, marks)
+
+	if len(marks) == 0 {
+		return nil, nil
+	}
+
+	prog, err := syntax.Compile(re.Simplify())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &boundaryVM{prog: prog, marks: marks, ncap: min(prog.NumCap, 4)}, nil
+}
+
+// collectMarks - находит группы-метки границ в разобранном выражении
+func collectMarks(re *syntax.Regexp, marks map[int]string) {
+	if re.Op == syntax.OpCapture {
+		switch re.Name {
+		case markWordStart, markWordEnd, markBoundary, markNotBoundary:
+			marks[2*re.Cap] = re.Name
+		}
+	}
+
+	for _, sub := range re.Sub {
+		collectMarks(sub, marks)
+	}
+}
+
+/*
+vmQueue - потоки машины на одной позиции строки, не больше одного потока на инструкцию
+sparse и dense - разреженное множество номеров инструкций, очищается за O(1)
+*/
+type vmQueue struct {
+	sparse []uint32
+	dense  []vmThread
+}
+
+/*
+vmThread - поток машины
+pc uint32 - инструкция
+cap []int - ячейки групп, cap[0] - начало совпадения, у промежуточных инструкций nil
+*/
+type vmThread struct {
+	pc  uint32
+	cap []int
+}
+
+func newVMQueue(n int) *vmQueue {
+	return &vmQueue{sparse: make([]uint32, n)}
+}
+
+func (q *vmQueue) contains(pc uint32) bool {
+	j := q.sparse[pc]
+	return int(j) < len(q.dense) && q.dense[j].pc == pc
+}
+
+/*
+find - метод находящий самое левое и из них самое длинное совпадение, которое начинается не раньше start
+строка проверяется целиком, поэтому ^ и границы слова у start видят предыдущие символы
+возвращает ячейки групп: начало и конец совпадения и первой группы, или nil
+*/
+func (vm *boundaryVM) find(line []byte, start int) []int {
+	clist, nlist := newVMQueue(len(vm.prog.Inst)), newVMQueue(len(vm.prog.Inst))
+	var match []int
+
+	for pos := start; ; {
+		r, width := runeAt(line, pos)
+
+		// новые потоки нужны, только пока совпадение не найдено: более правые начала не подходят
+		if match == nil {
+			cap := make([]int, vm.ncap)
+			for i := range cap {
+				cap[i] = -1
+			}
+			cap[0] = pos
+
+			vm.add(clist, uint32(vm.prog.Start), line, pos, cap, syntax.EmptyOpContext(runeBefore(line, pos), r))
+		}
+
+		if len(clist.dense) == 0 {
+			break
+		}
+
+		next, _ := runeAt(line, pos+width)
+		nextFlag := syntax.EmptyOpContext(r, next)
+
+		for _, t := range clist.dense {
+			if t.cap == nil || match != nil && t.cap[0] > match[0] {
+				continue
+			}
+
+			inst := &vm.prog.Inst[t.pc]
+
+			switch inst.Op {
+			case syntax.InstMatch:
+				if match == nil || t.cap[0] < match[0] || pos > match[1] {
+					match = append([]int(nil), t.cap...)
+					match[1] = pos
+				}
+			case syntax.InstRune, syntax.InstRune1, syntax.InstRuneAny, syntax.InstRuneAnyNotNL:
+				if width > 0 && matchRune(inst, r) {
+					vm.add(nlist, inst.Out, line, pos+width, t.cap, nextFlag)
+				}
+			}
+		}
+
+		if width == 0 {
+			break
+		}
+
+		clist, nlist = nlist, clist
+		nlist.dense = nlist.dense[:0]
+		pos += width
+	}
+
+	return match
+}
+
+// add - метод добавляющий поток и все инструкции, достижимые из pc без чтения символа
+func (vm *boundaryVM) add(q *vmQueue, pc uint32, line []byte, pos int, cap []int, flag syntax.EmptyOp) {
+	if q.contains(pc) {
+		return
+	}
+
+	j := len(q.dense)
+	q.dense = append(q.dense, vmThread{pc: pc})
+	q.sparse[pc] = uint32(j)
+
+	inst := &vm.prog.Inst[pc]
+
+	switch inst.Op {
+	case syntax.InstAlt, syntax.InstAltMatch:
+		vm.add(q, inst.Out, line, pos, cap, flag)
+		vm.add(q, inst.Arg, line, pos, cap, flag)
+	case syntax.InstEmptyWidth:
+		if syntax.EmptyOp(inst.Arg)&^flag == 0 {
+			vm.add(q, inst.Out, line, pos, cap, flag)
+		}
+	case syntax.InstNop:
+		vm.add(q, inst.Out, line, pos, cap, flag)
+	case syntax.InstCapture:
+		if name, ok := vm.marks[int(inst.Arg)]; ok && !markHolds(name, line, pos) {
+			return
+		}
+
+		if int(inst.Arg) < len(cap) {
+			cap = append([]int(nil), cap...)
+			cap[inst.Arg] = pos
+		}
+		vm.add(q, inst.Out, line, pos, cap, flag)
+	case syntax.InstMatch, syntax.InstRune, syntax.InstRune1, syntax.InstRuneAny, syntax.InstRuneAnyNotNL:
+		q.dense[j].cap = cap
+	}
+}
+
+// matchRune - подходит ли символ r под инструкцию чтения символа
+func matchRune(inst *syntax.Inst, r rune) bool {
+	switch inst.Op {
+	case syntax.InstRuneAny:
+		return true
+	case syntax.InstRuneAnyNotNL:
+		return r != '\n'
+	}
+
+	return inst.MatchRune(r)
+}
+
+// runeAt - символ в позиции pos и его длина, в конце строки -1 и 0
+func runeAt(line []byte, pos int) (rune, int) {
+	if pos >= len(line) {
+		return -1, 0
+	}
+
+	return utf8.DecodeRune(line[pos:])
+}
+
+// runeBefore - символ перед позицией pos, в начале строки -1
+func runeBefore(line []byte, pos int) rune {
+	if pos == 0 {
+		return -1
+	}
+
+	r, _ := utf8.DecodeLastRune(line[:pos])
+
+	return r
+}
+
+// markHolds - выполняется ли граница слова name в позиции pos строки
+func markHolds(name string, line []byte, pos int) bool {
+	r, _ := runeAt(line, pos)
+	before, after := isWordRune(runeBefore(line, pos)), isWordRune(r)
+
+	switch name {
+	case markWordStart:
+		return !before && after
+	case markWordEnd:
+		return before && !after
+	case markBoundary:
+		return before != after
+	}
+
+	return before == after
+}
+
+// isWordRune - символ слова: буква, цифра или '_', как в nonWord
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_'
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"io"
+	"strconv"
+)
+
+/*
+numberedLine - строка файла вместе с её номером
+num int - номер строки в файле, нумерация с нуля
+offset int64 - смещение начала строки от начала файла в байтах
+text string - сама строка
+*/
+type numberedLine struct {
+	num    int
+	offset int64
+	text   string
+}
+
+/*
+lineRing - кольцевой буфер последних строк перед совпадением для -B
+lines []numberedLine - буфер, его длина равна числу строк контекста
+start int - индекс самой старой строки
+size int - число строк в буфере
+*/
+type lineRing struct {
+	lines []numberedLine
+	start int
+	size  int
+}
+
+func newLineRing(n int) *lineRing {
+	return &lineRing{lines: make([]numberedLine, n)}
+}
+
+// push - метод добавляющий строку, при заполненном буфере вытесняется самая старая
+func (r *lineRing) push(line numberedLine) {
+	if len(r.lines) == 0 {
+		return
+	}
+
+	if r.size < len(r.lines) {
+		r.lines[(r.start+r.size)%len(r.lines)] = line
+		r.size++
+		return
+	}
+
+	r.lines[r.start] = line
+	r.start = (r.start + 1) % len(r.lines)
+}
+
+// drain - метод возвращающий строки буфера от старой к новой и очищающий буфер
+func (r *lineRing) drain() []numberedLine {
+	res := make([]numberedLine, 0, r.size)
+
+	for i := 0; i < r.size; i++ {
+		res = append(res, r.lines[(r.start+i)%len(r.lines)])
+	}
+	r.start, r.size = 0, 0
+
+	return res
+}
+
+// lineWriter - место вывода строк: bufio.Writer для стандартного вывода или bytes.Buffer для вывода одного файла
+type lineWriter interface {
+	io.Writer
+	io.StringWriter
+	io.ByteWriter
+}
+
+// цвета GNU grep по умолчанию: совпадение, имя файла, номер строки и смещение, разделители
+const (
+	colorMatch     = "01;31"
+	colorFile      = "35"
+	colorNumber    = "32"
+	colorSeparator = "36"
+)
+
+/*
+printer - вывод найденных строк и строк контекста
+w lineWriter - место вывода
+m matcher - поиск совпадений в строке для -o и --color
+context bool - задан контекст -A, -B или -C, тогда несмежные группы строк разделяются "--"
+withName bool - выводить имя файла перед строками
+lineNumbers bool - выводить номер строки (-n)
+byteOffsets bool - выводить смещение строки или совпадения в байтах (-b)
+onlyMatching bool - выводить только совпавшие части строк (-o)
+invert bool - найдены строки без совпадений (-v)
+color bool - подсвечивать совпадения, имена файлов и разделители (--color)
+file string - имя текущего файла
+last int - номер последней выведенной строки текущего файла, -1 - строк ещё не было
+printed bool - строки уже выводились, в том числе из предыдущих файлов
+*/
+type printer struct {
+	w            lineWriter
+	m            matcher
+	context      bool
+	withName     bool
+	lineNumbers  bool
+	byteOffsets  bool
+	onlyMatching bool
+	invert       bool
+	color        bool
+	file         string
+	last         int
+	printed      bool
+}
+
+func newPrinter(w lineWriter, ca *ConsoleArgs, m matcher, withName bool) *printer {
+	return &printer{
+		w:            w,
+		m:            m,
+		context:      ca.FlgColl["-A"].(int) > 0 || ca.FlgColl["-B"].(int) > 0,
+		withName:     withName,
+		lineNumbers:  ca.FlgColl["-n"].(bool),
+		byteOffsets:  ca.FlgColl["-b"].(bool),
+		onlyMatching: ca.FlgColl["-o"].(bool),
+		invert:       ca.FlgColl["-v"].(bool),
+		color:        ca.FlgColl["--color"].(bool),
+		last:         -1,
+	}
+}
+
+// startFile - метод начинающий вывод строк следующего файла
+func (p *printer) startFile(name string) {
+	p.file = name
+	p.last = -1
+}
+
+// name - метод выводящий имя текущего файла для -l и -L
+func (p *printer) name() error {
+	p.colored(p.file, colorFile)
+
+	return p.w.WriteByte('\n')
+}
+
+// count - метод выводящий для -c число найденных строк текущего файла, с именем файла "файл:число"
+func (p *printer) count(n int) error {
+	if p.withName {
+		p.colored(p.file, colorFile)
+		p.colored(":", colorSeparator)
+	}
+	p.w.WriteString(strconv.Itoa(n))
+
+	return p.w.WriteByte('\n')
+}
+
+// binaryMatches - метод выводящий вместо строк двоичного файла сообщение "Binary file файл matches"
+func (p *printer) binaryMatches() error {
+	_, err := p.w.WriteString("Binary file " + p.file + " matches\n")
+	return err
+}
+
+/*
+print - метод выводящий строку, перед группой строк, не примыкающей к предыдущей, выводится "--"
+перед строкой выводятся имя файла, номер строки и смещение, после найденной строки через ':', после строки
+контекста через '-', с -o выводятся только совпавшие части строк
+*/
+func (p *printer) print(line numberedLine, match bool) error {
+	if p.context && p.printed && (p.last < 0 || line.num > p.last+1) {
+		p.groupSeparator()
+	}
+	p.last = line.num
+	p.printed = true
+
+	sep := "-"
+	if match {
+		sep = ":"
+	}
+
+	if p.onlyMatching {
+		return p.printMatches(line, sep, match)
+	}
+
+	p.prefix(line, sep, line.offset)
+
+	// подсвечиваются совпадения в строках, подходящих под искомые значения: в найденных, а с -v в строках контекста
+	if p.color && match != p.invert {
+		p.highlight(line.text)
+	} else {
+		p.w.WriteString(line.text)
+	}
+
+	return p.w.WriteByte('\n')
+}
+
+// groupSeparator - метод выводящий "--" между несмежными группами строк
+func (p *printer) groupSeparator() error {
+	p.colored("--", colorSeparator)
+
+	return p.w.WriteByte('\n')
+}
+
+/*
+printMatches - метод выводящий для -o каждую совпавшую часть строки отдельной строкой
+совпадения есть в найденных строках, а с -v - только в строках контекста
+*/
+func (p *printer) printMatches(line numberedLine, sep string, match bool) error {
+	if match == p.invert {
+		return nil
+	}
+
+	var err error
+
+	for _, loc := range p.m.FindAllIndex([]byte(line.text), -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+
+		p.prefix(line, sep, line.offset+int64(loc[0]))
+		p.colored(line.text[loc[0]:loc[1]], colorMatch)
+		err = p.w.WriteByte('\n')
+	}
+
+	return err
+}
+
+// prefix - метод выводящий перед строкой имя файла, номер строки и смещение, каждое с разделителем sep
+func (p *printer) prefix(line numberedLine, sep string, offset int64) {
+	if p.withName {
+		p.colored(p.file, colorFile)
+		p.colored(sep, colorSeparator)
+	}
+
+	if p.lineNumbers {
+		p.colored(strconv.Itoa(line.num+1), colorNumber)
+		p.colored(sep, colorSeparator)
+	}
+
+	if p.byteOffsets {
+		p.colored(strconv.FormatInt(offset, 10), colorNumber)
+		p.colored(sep, colorSeparator)
+	}
+}
+
+// highlight - метод выводящий строку с подсвеченными совпадениями
+func (p *printer) highlight(text string) {
+	pos := 0
+
+	for _, loc := range p.m.FindAllIndex([]byte(text), -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+
+		p.w.WriteString(text[pos:loc[0]])
+		p.colored(text[loc[0]:loc[1]], colorMatch)
+		pos = loc[1]
+	}
+
+	p.w.WriteString(text[pos:])
+}
+
+/*
+colored - метод выводящий s, с --color в цвете sgr, как в GNU grep: после смены цвета строка
+очищается до конца (\x1b[K), чтобы фон не растягивался при переносе
+ошибки записи запоминаются в bufio.Writer и возвращаются последней записью строки
+*/
+func (p *printer) colored(s, sgr string) {
+	if !p.color {
+		p.w.WriteString(s)
+		return
+	}
+
+	p.w.WriteString("\x1b[" + sgr + "m\x1b[K")
+	p.w.WriteString(s)
+	p.w.WriteString("\x1b[m\x1b[K")
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"unicode"
+)
+
+// stdinName - имя стандартного ввода в выводе, как в GNU grep
+const stdinName = "(standard input)"
+
+/*
+eachFile - метод обходящий путь: файл, стандартный ввод ("-") или каталог (с -r), и вызывающий visit
+для каждого подходящего под --include и --exclude файла, ошибки открытия путей выводятся в errOut,
+ошибка visit прерывает обход и возвращается
+*/
+func (g *Grep) eachFile(path string, visit func(name string) error) error {
+	if path == "-" {
+		return visit(path)
+	}
+
+	info, err := os.Stat(path)
+
+	if err != nil {
+		g.reportError(path, err)
+		return nil
+	}
+
+	if !info.IsDir() {
+		if !g.included(path) {
+			return nil
+		}
+		return visit(path)
+	}
+
+	if !g.ca.FlgColl["-r"].(bool) {
+		g.reportError(path, errors.New("Is a directory"))
+		return nil
+	}
+
+	// файлы каталога обходятся в лексикографическом порядке, поэтому вывод не зависит от файловой системы
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			g.reportError(p, err)
+			return nil
+		}
+
+		if !d.Type().IsRegular() || !g.included(p) {
+			return nil
+		}
+
+		// явно указанный "." остаётся в выводе, как в GNU grep: ./a.txt
+		if path == "." && !g.implicitDir {
+			p = "./" + p
+		}
+
+		return visit(p)
+	})
+}
+
+// searchInput - метод ищущий в файле или в стандартном вводе, если name равно "-", с -z сжатые файлы распаковываются
+func (g *Grep) searchInput(name string, out *printer) (int, error) {
+	if name == "-" {
+		return g.search(os.Stdin, stdinName, out)
+	}
+
+	file, err := os.Open(name)
+
+	if err != nil {
+		g.reportError(name, err)
+		return 0, nil
+	}
+	defer file.Close()
+
+	r, err := g.decompress(file, name)
+
+	if err != nil {
+		g.reportError(name, err)
+		return 0, nil
+	}
+
+	return g.search(r, name, out)
+}
+
+// decompress - метод распаковывающий с -z файлы .gz и .bz2 по расширению, как zgrep, остальные файлы читаются как есть
+func (g *Grep) decompress(file io.Reader, name string) (io.Reader, error) {
+	if !g.ca.FlgColl["-z"].(bool) {
+		return file, nil
+	}
+
+	switch filepath.Ext(name) {
+	case ".gz":
+		return gzip.NewReader(file)
+	case ".bz2":
+		return bzip2.NewReader(file), nil
+	}
+
+	return file, nil
+}
+
+// included - файл подходит под --include и не подходит под --exclude, проверяется базовое имя файла
+func (g *Grep) included(path string) bool {
+	base := filepath.Base(path)
+
+	for _, glob := range g.ca.FlgColl["--exclude"].([]string) {
+		if ok, _ := filepath.Match(glob, base); ok {
+			return false
+		}
+	}
+
+	include := g.ca.FlgColl["--include"].([]string)
+
+	for _, glob := range include {
+		if ok, _ := filepath.Match(glob, base); ok {
+			return true
+		}
+	}
+
+	return len(include) == 0
+}
+
+/*
+reportError - метод выводящий ошибку файла в виде "grep: путь: Причина", как в GNU grep
+с -s сообщение не выводится, но ошибка всё равно влияет на код завершения
+*/
+func (g *Grep) reportError(path string, err error) {
+	g.failed.Store(true)
+
+	if g.ca.FlgColl["-s"].(bool) {
+		return
+	}
+
+	var pathErr *fs.PathError
+
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+
+	msg := []rune(err.Error())
+	if len(msg) > 0 {
+		msg[0] = unicode.ToUpper(msg[0])
+	}
+
+	fmt.Fprintf(g.errOut, "grep: %s: %s\n", path, string(msg))
+}
@@ -0,0 +1,426 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// nonWord - символ, не входящий в слово для -w: слово состоит из букв, цифр и '_', как в GNU grep
+const nonWord = `[^\pL\pN_]`
+
+/*
+matcher - поиск искомых значений в строке, ему удовлетворяет и *regexp.Regexp
+Match - подходит ли строка
+FindAllIndex - границы непересекающихся совпадений слева направо, не больше n, при n < 0 все, для -o и --color
+*/
+type matcher interface {
+	Match(line []byte) bool
+	FindAllIndex(line []byte, n int) [][]int
+}
+
+/*
+newMatcher - создаёт matcher для искомых значений patterns с учётом флагов -F, -G, -E, -P, -i, -w и -x
+строка подходит, если подходит хотя бы одно значение, без значений не подходит ни одна строка
+выражения POSIX -G (по умолчанию) и -E переводятся в синтаксис Go, выражения -P почти совпадают с ним
+строки без -i, -w и -x ищутся без регулярных выражений, остальные режимы сводятся к одному выражению,
+из совпадений, начинающихся в одном месте, выбирается самое длинное, как в GNU grep
+*/
+func newMatcher(patterns []string, flags map[string]interface{}) (matcher, error) {
+	fixed, ignCase := flags["-F"].(bool), flags["-i"].(bool)
+	word, line := flags["-w"].(bool), flags["-x"].(bool)
+
+	if len(patterns) == 0 {
+		return noMatch{}, nil
+	}
+
+	if fixed && !ignCase && !word {
+		switch {
+		case line:
+			return newLineSet(patterns), nil
+		case len(patterns) == 1:
+			return literal(patterns[0]), nil
+		default:
+			return newAhoCorasick(patterns), nil
+		}
+	}
+
+	alts := make([]string, len(patterns))
+	for i, p := range patterns {
+		var err error
+
+		switch {
+		case fixed:
+			p = regexp.QuoteMeta(p)
+		case flags["-P"].(bool):
+			err = checkPerl(p)
+		default:
+			p, err = translatePOSIX(p, flags["-E"].(bool))
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		// каждое выражение проверяется отдельно, чтобы ошибка не ссылалась на соседние
+		if _, err := regexp.Compile(p); err != nil {
+			return nil, err
+		}
+		alts[i] = "(?:" + p + ")"
+	}
+	expr := strings.Join(alts, "|")
+
+	// -x важнее -w, как в GNU grep
+	switch {
+	case line:
+		expr = "^(?:" + expr + ")$"
+	case word:
+		expr = "(?:^|" + nonWord + ")(" + expr + ")(?:" + nonWord + "|$)"
+	}
+
+	if ignCase {
+		expr = "(?i)" + expr
+	}
+
+	re, err := regexp.Compile(expr)
+
+	if err != nil {
+		return nil, err
+	}
+	re.Longest()
+
+	// границы слова \<, \>, \b и \B проверяются во время поиска, RE2 служит только быстрым отсевом строк
+	vm, err := newBoundaryVM(expr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if word && !line {
+		return wordMatcher{re: re, vm: vm}, nil
+	}
+
+	if vm != nil {
+		return boundaryMatcher{re: re, vm: vm}, nil
+	}
+
+	return re, nil
+}
+
+/*
+wordMatcher - поиск целых слов для -w
+re *regexp.Regexp - выражение, захватывающее соседние со словом символы, само слово - первая группа
+vm *boundaryVM - поиск с границами слова \<, \>, \b и \B внутри искомых значений, nil без них
+*/
+type wordMatcher struct {
+	re *regexp.Regexp
+	vm *boundaryVM
+}
+
+func (wm wordMatcher) Match(line []byte) bool {
+	if wm.vm == nil {
+		return wm.re.Match(line)
+	}
+
+	return wm.re.Match(line) && wm.vm.find(line, 0) != nil
+}
+
+func (wm wordMatcher) FindAllIndex(line []byte, n int) [][]int {
+	var res [][]int
+
+	for start := 0; start <= len(line) && (n < 0 || len(res) < n); {
+		loc := wm.find(line, start)
+
+		if loc == nil {
+			break
+		}
+
+		begin, end := loc[2], loc[3]
+
+		// символ после слова может быть началом следующего совпадения, поэтому поиск продолжается с конца слова
+		if begin == end {
+			_, size := utf8.DecodeRune(line[end:])
+			start = end + max(size, 1)
+			continue
+		}
+
+		res = append(res, []int{begin, end})
+		start = end
+	}
+
+	return res
+}
+
+// find - метод находящий совпадение, которое начинается не раньше start, границы отсчитываются от начала строки
+func (wm wordMatcher) find(line []byte, start int) []int {
+	if wm.vm != nil {
+		return wm.vm.find(line, start)
+	}
+
+	loc := wm.re.FindSubmatchIndex(line[start:])
+
+	for i := range loc {
+		if loc[i] >= 0 {
+			loc[i] += start
+		}
+	}
+
+	return loc
+}
+
+/*
+boundaryMatcher - поиск выражения с границами слова \<, \>, \b и \B
+re *regexp.Regexp - то же выражение, где границы - пустые группы, строки без его совпадений сразу отбрасываются
+vm *boundaryVM - поиск самого левого и самого длинного совпадения с верными границами
+*/
+type boundaryMatcher struct {
+	re *regexp.Regexp
+	vm *boundaryVM
+}
+
+func (bm boundaryMatcher) Match(line []byte) bool {
+	return bm.re.Match(line) && bm.vm.find(line, 0) != nil
+}
+
+// FindAllIndex - метод находящий совпадения как regexp, пустое совпадение сразу после предыдущего пропускается
+func (bm boundaryMatcher) FindAllIndex(line []byte, n int) [][]int {
+	var res [][]int
+
+	for start, prevEnd := 0, -1; start <= len(line) && (n < 0 || len(res) < n); {
+		loc := bm.vm.find(line, start)
+
+		if loc == nil {
+			break
+		}
+
+		if loc[0] < loc[1] || loc[0] != prevEnd {
+			res = append(res, loc[:2])
+		}
+		prevEnd = loc[1]
+
+		start = loc[1]
+		if loc[0] == loc[1] {
+			_, size := utf8.DecodeRune(line[start:])
+			start += max(size, 1)
+		}
+	}
+
+	return res
+}
+
+// noMatch - matcher без искомых значений, например для пустого файла -f
+type noMatch struct{}
+
+func (noMatch) Match([]byte) bool {
+	return false
+}
+
+func (noMatch) FindAllIndex([]byte, int) [][]int {
+	return nil
+}
+
+// literal - одна искомая строка для -F
+type literal string
+
+func (l literal) Match(line []byte) bool {
+	return bytes.Contains(line, []byte(l))
+}
+
+// FindAllIndex - метод находящий вхождения строки, пустая строка совпадает только с пустыми частями и не ищется
+func (l literal) FindAllIndex(line []byte, n int) [][]int {
+	var res [][]int
+
+	if len(l) == 0 {
+		return nil
+	}
+
+	for start := 0; n < 0 || len(res) < n; {
+		i := bytes.Index(line[start:], []byte(l))
+
+		if i < 0 {
+			break
+		}
+
+		res = append(res, []int{start + i, start + i + len(l)})
+		start += i + len(l)
+	}
+
+	return res
+}
+
+// lineSet - искомые строки для -F -x, строка подходит, если совпадает с одной из них целиком
+type lineSet map[string]struct{}
+
+func newLineSet(patterns []string) lineSet {
+	set := make(lineSet, len(patterns))
+
+	for _, p := range patterns {
+		set[p] = struct{}{}
+	}
+
+	return set
+}
+
+func (s lineSet) Match(line []byte) bool {
+	_, ok := s[string(line)]
+	return ok
+}
+
+func (s lineSet) FindAllIndex(line []byte, n int) [][]int {
+	if n == 0 || !s.Match(line) {
+		return nil
+	}
+
+	return [][]int{{0, len(line)}}
+}
+
+/*
+ahoCorasick - автомат Ахо - Корасик для -F с несколькими строками, все строки ищутся за один проход
+nodes []acNode - вершины бора искомых строк, 0 - корень
+root [256]int32 - переходы из корня по всем байтам, в корне автомат бывает чаще всего
+*/
+type ahoCorasick struct {
+	nodes []acNode
+	root  [256]int32
+}
+
+/*
+acNode - вершина бора
+next map[byte]int32 - переходы по следующему байту
+fail int32 - вершина наибольшего собственного суффикса пути до этой вершины, который есть в боре
+end bool - в этой вершине заканчивается искомая строка
+out bool - в этой вершине или в одном из её суффиксов заканчивается искомая строка
+*/
+type acNode struct {
+	next map[byte]int32
+	fail int32
+	end  bool
+	out  bool
+}
+
+func newAhoCorasick(patterns []string) *ahoCorasick {
+	ac := &ahoCorasick{nodes: []acNode{{next: make(map[byte]int32)}}}
+
+	for _, p := range patterns {
+		cur := int32(0)
+
+		for i := 0; i < len(p); i++ {
+			nxt, ok := ac.nodes[cur].next[p[i]]
+
+			if !ok {
+				nxt = int32(len(ac.nodes))
+				ac.nodes = append(ac.nodes, acNode{next: make(map[byte]int32)})
+				ac.nodes[cur].next[p[i]] = nxt
+			}
+			cur = nxt
+		}
+		ac.nodes[cur].end = true
+		ac.nodes[cur].out = true
+	}
+
+	// ссылки на суффиксы считаются обходом в ширину: у вершин меньшей глубины они уже известны
+	queue := make([]int32, 0, len(ac.nodes))
+	for b, child := range ac.nodes[0].next {
+		ac.root[b] = child
+		queue = append(queue, child)
+	}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		for b, child := range ac.nodes[cur].next {
+			fail := ac.nodes[cur].fail
+
+			for fail != 0 {
+				if _, ok := ac.nodes[fail].next[b]; ok {
+					break
+				}
+				fail = ac.nodes[fail].fail
+			}
+
+			if nxt, ok := ac.nodes[fail].next[b]; ok {
+				ac.nodes[child].fail = nxt
+			}
+			ac.nodes[child].out = ac.nodes[child].out || ac.nodes[ac.nodes[child].fail].out
+
+			queue = append(queue, child)
+		}
+	}
+
+	return ac
+}
+
+func (ac *ahoCorasick) Match(line []byte) bool {
+	// пустая искомая строка есть в любой строке
+	if ac.nodes[0].out {
+		return true
+	}
+
+	cur := int32(0)
+
+	for _, b := range line {
+		for cur != 0 {
+			if nxt, ok := ac.nodes[cur].next[b]; ok {
+				cur = nxt
+				break
+			}
+			cur = ac.nodes[cur].fail
+		}
+
+		if cur == 0 {
+			cur = ac.root[b]
+		}
+
+		if ac.nodes[cur].out {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+FindAllIndex - метод находящий самые левые и из них самые длинные вхождения строк, как в GNU grep
+совпадения ищутся только в уже найденных строках, поэтому для каждой позиции бор обходится заново
+*/
+func (ac *ahoCorasick) FindAllIndex(line []byte, n int) [][]int {
+	var res [][]int
+
+	for start := 0; start < len(line) && (n < 0 || len(res) < n); {
+		end := ac.longestAt(line, start)
+
+		// пустые совпадения не выводятся
+		if end <= start {
+			start++
+			continue
+		}
+
+		res = append(res, []int{start, end})
+		start = end
+	}
+
+	return res
+}
+
+// longestAt - метод возвращающий конец самой длинной искомой строки, которая начинается в start, или -1
+func (ac *ahoCorasick) longestAt(line []byte, start int) int {
+	end := -1
+	cur := int32(0)
+
+	for i := start; i < len(line); i++ {
+		nxt, ok := ac.nodes[cur].next[line[i]]
+
+		if !ok {
+			break
+		}
+		cur = nxt
+
+		if ac.nodes[cur].end {
+			end = i + 1
+		}
+	}
+
+	return end
+}
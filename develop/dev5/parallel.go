@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// chunkSize - размер части входных данных, часть дочитывается до конца строки
+const chunkSize = 1 << 20
+
+// minChunkLines - меньше строк на горутину не делим, накладные расходы превысят выигрыш
+const minChunkLines = 1 << 12
+
+// errStopped - обход файлов прерван, потому что вывод результата завершился ошибкой
+var errStopped = errors.New("search stopped")
+
+// DefaultParallel - число горутин по умолчанию, не больше 8
+func DefaultParallel() int {
+	return min(runtime.NumCPU(), 8)
+}
+
+/*
+chunkReader - чтение входных данных частями, разделёнными по границам строк
+r *bufio.Reader - источник данных
+buf []byte - буфер текущей части, переиспользуется между частями
+lines [][]byte - строки текущей части без '\n'
+*/
+type chunkReader struct {
+	r     *bufio.Reader
+	buf   []byte
+	lines [][]byte
+}
+
+func newChunkReader(r io.Reader) *chunkReader {
+	return &chunkReader{
+		r:   bufio.NewReader(r),
+		buf: make([]byte, chunkSize),
+	}
+}
+
+/*
+next - метод читающий следующую часть, которая заканчивается '\n' или концом данных
+строки действительны до следующего вызова, в конце данных вместе с последними строками возвращается io.EOF
+*/
+func (c *chunkReader) next() ([][]byte, error) {
+	n, err := io.ReadFull(c.r, c.buf[:chunkSize])
+	data := c.buf[:n]
+
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+
+	// строка не делится между частями, поэтому часть дочитывается до её конца
+	if err == nil && data[n-1] != '\n' {
+		var rest []byte
+
+		rest, err = c.r.ReadBytes('\n')
+		data = append(data, rest...)
+		c.buf = data[:cap(data)]
+	}
+
+	c.lines = c.lines[:0]
+
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+
+		if i < 0 {
+			c.lines = append(c.lines, data)
+			break
+		}
+
+		c.lines = append(c.lines, data[:i])
+		data = data[i+1:]
+	}
+
+	return c.lines, err
+}
+
+// matchLines - метод проверяющий строки одной части, при большом числе строк они делятся между горутинами
+func (g *Grep) matchLines(lines [][]byte) []bool {
+	matched := make([]bool, len(lines))
+	workers := max(1, min(g.workers, len(lines)/minChunkLines))
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(lo, hi int) {
+			defer wg.Done()
+			for i := lo; i < hi; i++ {
+				matched[i] = g.match(lines[i])
+			}
+		}(w*len(lines)/workers, (w+1)*len(lines)/workers)
+	}
+	wg.Wait()
+
+	return matched
+}
+
+/*
+fileJob - поиск в одном файле пулом горутин
+name string - путь до файла, у последнего задания пустой, оно несёт только ошибки обхода
+errsBefore bytes.Buffer - ошибки обхода путей, найденные перед этим файлом
+out bytes.Buffer - вывод файла
+errs bytes.Buffer - ошибки чтения файла
+matches int - число найденных строк
+lines bool - выводились строки, тогда перед ними может понадобиться "--"
+err error - ошибка поиска
+done chan struct{} - закрывается, когда поиск в файле завершён
+*/
+type fileJob struct {
+	name       string
+	errsBefore bytes.Buffer
+	out        bytes.Buffer
+	errs       bytes.Buffer
+	matches    int
+	lines      bool
+	err        error
+	done       chan struct{}
+}
+
+func newFileJob() *fileJob {
+	return &fileJob{done: make(chan struct{})}
+}
+
+// run - метод ищущий в файле задания, строки файла проверяются в одной горутине, остальные заняты другими файлами
+func (job *fileJob) run(g *Grep, withName bool) {
+	defer close(job.done)
+
+	jg := *g
+	jg.errOut = &job.errs
+	jg.workers = 1
+
+	out := newPrinter(&job.out, g.ca, g.m, withName)
+	job.matches, job.err = jg.searchInput(job.name, out)
+	job.lines = out.printed
+}
+
+/*
+grepFiles - метод ищущий в файлах пулом из workers горутин
+вывод каждого файла копится в своём буфере и пишется в w в порядке файлов, поэтому строки разных
+файлов не перемешиваются, одновременно в работе не больше 2 * workers файлов
+с -q поиск, как и последовательный, заканчивается на первом по порядку файле с совпадением
+*/
+func (g *Grep) grepFiles(w lineWriter, withName bool) (int, error) {
+	jobs := make(chan *fileJob)
+	results := make(chan *fileJob, 2*g.workers)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	for i := 0; i < g.workers; i++ {
+		go func() {
+			for job := range jobs {
+				job.run(g, withName)
+			}
+		}()
+	}
+
+	go g.produceJobs(jobs, results, stop)
+
+	out := newPrinter(w, g.ca, g.m, withName)
+	quiet := g.ca.FlgColl["-q"].(bool)
+	printed := false
+	total := 0
+
+	for job := range results {
+		g.errOut.Write(job.errsBefore.Bytes())
+		<-job.done
+
+		// группы строк разных файлов разделяются "--", как и при последовательном поиске
+		if out.context && printed && job.lines {
+			if err := out.groupSeparator(); err != nil {
+				return total, err
+			}
+		}
+		printed = printed || job.lines
+
+		if _, err := w.Write(job.out.Bytes()); err != nil {
+			return total, err
+		}
+		g.errOut.Write(job.errs.Bytes())
+
+		total += job.matches
+
+		if job.err != nil {
+			return total, job.err
+		}
+
+		// с -q поиск заканчивается на первом совпадении, остальные файлы и их ошибки не нужны
+		if quiet && total > 0 {
+			return total, nil
+		}
+	}
+
+	return total, nil
+}
+
+/*
+produceJobs - метод обходящий пути и отправляющий файлы в пул, каждое задание сначала попадает в results,
+чтобы сохранить порядок вывода, ошибки обхода прикладываются к следующему заданию
+*/
+func (g *Grep) produceJobs(jobs, results chan<- *fileJob, stop <-chan struct{}) {
+	defer close(results)
+	defer close(jobs)
+
+	pg := *g
+	job := newFileJob()
+	pg.errOut = &job.errsBefore
+
+	for _, path := range g.files {
+		err := pg.eachFile(path, func(name string) error {
+			job.name = name
+
+			select {
+			case results <- job:
+			case <-stop:
+				return errStopped
+			}
+
+			select {
+			case jobs <- job:
+			case <-stop:
+				return errStopped
+			}
+
+			job = newFileJob()
+			pg.errOut = &job.errsBefore
+
+			return nil
+		})
+
+		if err != nil {
+			return
+		}
+	}
+
+	close(job.done)
+
+	select {
+	case results <- job:
+	case <-stop:
+	}
+}
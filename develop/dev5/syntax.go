@@ -0,0 +1,435 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// errBackref - обратные ссылки не поддерживаются RE2, в котором поиск всегда линейный
+var errBackref = errors.New("backreferences are not supported")
+
+/*
+метки границ слова: \b в RE2 знает только ASCII, а проверок назад нет, поэтому \<, \>, \b и \B
+становятся пустыми именованными группами, которые boundaryVM проверяет во время поиска,
+слово состоит из букв, цифр и '_', как для -w
+*/
+const (
+	markWordStart   = "grepWordStart"
+	markWordEnd     = "grepWordEnd"
+	markBoundary    = "grepBoundary"
+	markNotBoundary = "grepNotBoundary"
+)
+
+// wordClass - символ слова для \w, как у -w, \w в RE2 знает только ASCII
+const wordClass = `[\pL\pN_]`
+
+/*
+translatePOSIX - переводит регулярное выражение POSIX в синтаксис Go (RE2)
+extended bool - расширенный синтаксис -E, иначе базовый -G
+в базовом синтаксисе ( ) { } | + ? - обычные символы, а операторами становятся после '\', в расширенном наоборот,
+повторение в начале выражения, группы или альтернативы в базовом синтаксисе - обычный символ, а в расширенном
+пропускается, в базовом синтаксисе '^' и '$' - якоря только в начале и в конце выражения, группы или альтернативы,
+в расширенном одиночная ')' - обычный символ, как в GNU grep
+*/
+func translatePOSIX(pattern string, extended bool) (string, error) {
+	t := &posixTranslator{pattern: pattern, extended: extended, atStart: true, atom: -1}
+
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+
+		switch {
+		case c == '\\':
+			if i+1 >= len(pattern) {
+				return "", errors.New("trailing backslash (\\)")
+			}
+			r, size := utf8.DecodeRuneInString(pattern[i+1:])
+			i += 1 + size
+
+			// в базовом синтаксисе операторы записываются через '\'
+			if !extended && strings.ContainsRune("(){|+?", r) {
+				n, err := t.operator(i, byte(r))
+				if err != nil {
+					return "", err
+				}
+				i = n
+				continue
+			}
+
+			t.startAtom()
+			if err := translateEscape(&t.sb, r); err != nil {
+				return "", err
+			}
+
+		case c == '[':
+			t.startAtom()
+			n, err := translateBracket(&t.sb, pattern, i)
+			if err != nil {
+				return "", err
+			}
+			i = n
+
+		case c == '^':
+			i++
+
+			if !extended && !t.atStart {
+				t.startAtom()
+				t.sb.WriteString(`\^`)
+				continue
+			}
+
+			t.startAtom()
+			t.sb.WriteByte('^')
+
+			// в базовом синтаксисе '*' после начального '^' - обычный символ, в расширенном повторяет якорь
+			t.atStart = !extended
+
+		case c == '$':
+			t.startAtom()
+			if extended || anchorEnd(pattern[i+1:]) {
+				t.sb.WriteByte('$')
+			} else {
+				t.sb.WriteString(`\$`)
+			}
+			i++
+
+		case c == '*' || extended && strings.IndexByte("(){}|+?", c) >= 0:
+			n, err := t.operator(i+1, c)
+			if err != nil {
+				return "", err
+			}
+			i = n
+
+		case strings.IndexByte("(){}|+?", c) >= 0:
+			t.startAtom()
+			t.sb.WriteString(regexp.QuoteMeta(string(c)))
+			i++
+
+		default:
+			// символ записывается целиком, чтобы повторение, взятое в группу, относилось ко всему символу
+			_, size := utf8.DecodeRuneInString(pattern[i:])
+			t.startAtom()
+			t.sb.WriteString(pattern[i : i+size])
+			i += size
+		}
+	}
+
+	if len(t.groups) > 0 {
+		return "", errors.New("Unmatched ( or \\(")
+	}
+
+	return t.sb.String(), nil
+}
+
+/*
+posixTranslator - состояние перевода выражения POSIX
+sb strings.Builder - переведённое выражение
+pattern string - исходное выражение
+extended bool - расширенный синтаксис -E
+atStart bool - позиция в начале выражения, группы или альтернативы
+groups []int - начала открытых групп в sb
+atom int - начало в sb последнего атома, к которому относится повторение, -1 - атома нет
+repeated bool - к последнему атому уже применено повторение
+*/
+type posixTranslator struct {
+	sb       strings.Builder
+	pattern  string
+	extended bool
+	atStart  bool
+	groups   []int
+	atom     int
+	repeated bool
+}
+
+// startAtom - метод отмечающий начало следующего атома выражения
+func (t *posixTranslator) startAtom() {
+	t.atom = t.sb.Len()
+	t.repeated = false
+	t.atStart = false
+}
+
+/*
+operator - метод переводящий оператор c: группу, повторение или альтернативу, i - позиция после оператора
+'{' без правильного интервала в расширенном синтаксисе - обычный символ
+возвращает позицию после оператора вместе с интервалом
+*/
+func (t *posixTranslator) operator(i int, c byte) (int, error) {
+	switch c {
+	case '(':
+		t.groups = append(t.groups, t.sb.Len())
+		t.sb.WriteByte('(')
+		t.atStart = true
+		t.atom = -1
+
+		return i, nil
+
+	case ')':
+		if len(t.groups) == 0 {
+			if !t.extended {
+				return 0, errors.New("Unmatched ) or \\)")
+			}
+
+			t.startAtom()
+			t.sb.WriteString(`\)`)
+			return i, nil
+		}
+		t.sb.WriteByte(')')
+
+		// группа целиком - атом для следующего повторения
+		t.atom = t.groups[len(t.groups)-1]
+		t.groups = t.groups[:len(t.groups)-1]
+		t.repeated = false
+		t.atStart = false
+
+		return i, nil
+
+	case '|':
+		t.sb.WriteByte('|')
+		t.atStart = true
+		t.atom = -1
+
+		return i, nil
+
+	case '}':
+		t.startAtom()
+		t.sb.WriteString(`\}`)
+
+		return i, nil
+	}
+
+	op, n := string(c), i
+
+	if c == '{' {
+		closing := "}"
+		if !t.extended {
+			closing = `\}`
+		}
+
+		interval, size, ok := parseInterval(t.pattern[i:], closing)
+
+		if !ok {
+			if !t.extended {
+				return 0, errors.New("Unmatched \\{")
+			}
+
+			t.startAtom()
+			t.sb.WriteString(`\{`)
+			return i, nil
+		}
+		op, n = interval, i+size
+	}
+
+	if t.atStart {
+		if t.extended {
+			return n, nil
+		}
+
+		t.startAtom()
+		t.sb.WriteString(regexp.QuoteMeta(string(c)))
+		return i, nil
+	}
+
+	t.repeat(op)
+
+	return n, nil
+}
+
+/*
+repeat - метод применяющий повторение op к последнему атому
+RE2 не повторяет повторения и якоря, поэтому такой атом сначала берётся в группу: a** - (?:a*)*, ^* - (?:^)*
+*/
+func (t *posixTranslator) repeat(op string) {
+	s := t.sb.String()
+	atom := s[t.atom:]
+
+	if t.repeated || atom == "^" || atom == "$" || atom == `\A` || atom == `\z` {
+		t.sb.Reset()
+		t.sb.WriteString(s[:t.atom])
+		t.sb.WriteString("(?:" + atom + ")")
+	}
+
+	t.sb.WriteString(op)
+	t.repeated = true
+}
+
+/*
+parseInterval - разбирает интервал повторения m}, m,}, m,n} или ,n} до закрывающей скобки closing
+возвращает интервал в синтаксисе Go, число разобранных байт и успешность разбора
+*/
+func parseInterval(s, closing string) (string, int, bool) {
+	end := strings.Index(s, closing)
+
+	if end < 0 {
+		return "", 0, false
+	}
+
+	body := s[:end]
+	lo, hi, hasComma := strings.Cut(body, ",")
+
+	if !isDigits(lo) && !(lo == "" && hasComma) || hasComma && hi != "" && !isDigits(hi) {
+		return "", 0, false
+	}
+
+	if lo == "" {
+		lo = "0"
+	}
+
+	if !hasComma {
+		return "{" + lo + "}", end + len(closing), true
+	}
+
+	return "{" + lo + "," + hi + "}", end + len(closing), true
+}
+
+// isDigits - строка не пустая и состоит только из десятичных цифр
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// anchorEnd - '$' перед rest в базовом синтаксисе является якорем: в конце выражения, группы или альтернативы
+func anchorEnd(rest string) bool {
+	return rest == "" || strings.HasPrefix(rest, `\)`) || strings.HasPrefix(rest, `\|`)
+}
+
+/*
+translateEscape - переводит символ после '\', который не является оператором
+\< и \> - начало и конец слова, \b и \B - граница слова и её отсутствие, \w и \W - символ слова и не слова,
+всё это с буквами любого алфавита, \` и \' - начало и конец строки, \s и \S совпадают с Go, остальные символы после '\' - обычные
+*/
+func translateEscape(sb *strings.Builder, r rune) error {
+	switch {
+	case r >= '1' && r <= '9':
+		return fmt.Errorf("%w: \\%c", errBackref, r)
+	case r == '<':
+		sb.WriteString("(?P<" + markWordStart + ">)")
+	case r == '>':
+		sb.WriteString("(?P<" + markWordEnd + ">)")
+	case r == 'b':
+		sb.WriteString("(?P<" + markBoundary + ">)")
+	case r == 'B':
+		sb.WriteString("(?P<" + markNotBoundary + ">)")
+	case r == 'w':
+		sb.WriteString(wordClass)
+	case r == 'W':
+		sb.WriteString(nonWord)
+	case r == '`':
+		sb.WriteString(`\A`)
+	case r == '\'':
+		sb.WriteString(`\z`)
+	case r == 's' || r == 'S':
+		sb.WriteByte('\\')
+		sb.WriteRune(r)
+	default:
+		sb.WriteString(regexp.QuoteMeta(string(r)))
+	}
+
+	return nil
+}
+
+/*
+translateBracket - переводит выражение в квадратных скобках, начинающееся в позиции i
+в POSIX '\' внутри скобок - обычный символ, ']' сразу после '[' или '[^' - часть набора,
+[:класс:] переносится как есть, [=x=] и [.x.] заменяются на сам символ x
+возвращает позицию после закрывающей ']'
+*/
+func translateBracket(sb *strings.Builder, pattern string, i int) (int, error) {
+	errUnmatched := errors.New("Unmatched [, [^, [:, [., or [=")
+
+	sb.WriteByte('[')
+	i++
+
+	if i < len(pattern) && pattern[i] == '^' {
+		sb.WriteByte('^')
+		i++
+	}
+
+	if i < len(pattern) && pattern[i] == ']' {
+		sb.WriteString(`\]`)
+		i++
+	}
+
+	for i < len(pattern) {
+		c := pattern[i]
+
+		switch {
+		case c == ']':
+			sb.WriteByte(']')
+			return i + 1, nil
+
+		case c == '[' && i+1 < len(pattern) && strings.IndexByte(":=.", pattern[i+1]) >= 0:
+			kind := pattern[i+1]
+			end := strings.Index(pattern[i+2:], string(kind)+"]")
+
+			if end < 0 {
+				return 0, errUnmatched
+			}
+
+			name := pattern[i+2 : i+2+end]
+			if kind == ':' {
+				sb.WriteString("[:" + name + ":]")
+			} else {
+				writeClassLiteral(sb, name)
+			}
+			i += 2 + end + 2
+
+		case c == '\\' || c == '[':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+			i++
+
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+
+	return 0, errUnmatched
+}
+
+// writeClassLiteral - записывает символы s внутрь набора в скобках, знаки препинания экранируются, чтобы '-' не стал диапазоном
+func writeClassLiteral(sb *strings.Builder, s string) {
+	for _, r := range s {
+		if r < utf8.RuneSelf && (unicode.IsPunct(r) || unicode.IsSymbol(r)) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+}
+
+/*
+checkPerl - проверяет выражение -P, синтаксис Perl почти совпадает с Go, кроме обратных ссылок
+и проверок вперёд и назад, для них выводится понятная ошибка
+*/
+func checkPerl(pattern string) error {
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == '\\' && i+1 < len(pattern) {
+			if pattern[i+1] >= '1' && pattern[i+1] <= '9' {
+				return fmt.Errorf("%w: \\%c", errBackref, pattern[i+1])
+			}
+			i++
+
+			continue
+		}
+
+		for _, prefix := range []string{"(?=", "(?!", "(?<=", "(?<!", "(?>"} {
+			if strings.HasPrefix(pattern[i:], prefix) {
+				return fmt.Errorf("lookaround and atomic groups are not supported: %s", prefix)
+			}
+		}
+	}
+
+	return nil
+}
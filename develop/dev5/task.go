@@ -5,9 +5,11 @@ import (
 	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
 	"os"
-	"regexp"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 )
 
 /*
@@ -18,52 +20,35 @@ type ConsoleArgs struct {
 	FlgColl map[string]interface{}
 }
 
-// CollectAfter - структура отвечающая за получение элементов после найденого
-type CollectAfter struct {
-	ca *ConsoleArgs
-}
-
-// CollectBefore - структура отвечающая за получение элементов до найденого
-type CollectBefore struct {
-	ca *ConsoleArgs
-}
-
-// CollectAB - структура отвечающая за получение элементов до и после найденого
-type CollectAB struct {
-	ca *ConsoleArgs
-}
-
 func NewGrep(ca *ConsoleArgs) *Grep {
 	return &Grep{
-		ca: ca,
-		colA: &CollectAfter{
-			ca: ca,
-		},
-		colB: &CollectBefore{
-			ca: ca,
-		},
-		colC: &CollectAB{
-			ca: ca,
-		},
+		ca:      ca,
+		errOut:  os.Stderr,
+		workers: ca.FlgColl["--parallel"].(int),
+		failed:  new(atomic.Bool),
 	}
 }
 
 /*
-Grep - структура фильтрующая данные из файла
-ca       *ConsoleArgs - аргументы с которыми запущена программа
-colA     Collector - структура для получения элементов после найденого, реализует интерфейс Collector
-colB     Collector - структура для получения элементов до найденого, реализует интерфейс Collector
-colC     Collector - структура для получения элементов до и после найденого, реализует интерфейс Collector
-target   string - искомое значение
-filePath string - путь до файла
+Grep - структура фильтрующая данные из файлов
+ca     *ConsoleArgs - аргументы с которыми запущена программа
+patterns []string - искомые значения, строка подходит, если подходит хотя бы одно
+files  []string - пути до файлов и каталогов, "-" - стандартный ввод
+errOut io.Writer - вывод сообщений об ошибках чтения файлов
+implicitDir bool - каталог "." не указан явно, а подставлен для -r, тогда пути выводятся без "./"
+m matcher - поиск искомых значений, создаётся один раз перед поиском
+workers int - число горутин для поиска
+failed *atomic.Bool - при поиске были ошибки открытия или чтения файлов, общий для копий Grep в горутинах
 */
 type Grep struct {
-	ca       *ConsoleArgs
-	colA     Collector
-	colB     Collector
-	colC     Collector
-	target   string
-	filePath string
+	ca          *ConsoleArgs
+	patterns    []string
+	files       []string
+	errOut      io.Writer
+	implicitDir bool
+	m           matcher
+	workers     int
+	failed      *atomic.Bool
 }
 
 // ParseFlags - метод парсит флаги из консоли
@@ -74,12 +59,75 @@ func (ca *ConsoleArgs) ParseFlags() {
 	c := flag.Bool("c", false, "print count lines")
 	i := flag.Bool("i", false, "ignoring register")
 	v := flag.Bool("v", false, "invert filter")
-	F := flag.Bool("F", false, "full line match")
-	n := flag.Bool("n", false, "print number of lines match")
+	F := flag.Bool("F", false, "interpret patterns as fixed strings, not regular expressions")
+	G := flag.Bool("G", false, "interpret patterns as basic regular expressions, the default")
+	E := flag.Bool("E", false, "interpret patterns as extended regular expressions")
+	P := flag.Bool("P", false, "interpret patterns as Perl-like regular expressions")
+	x := flag.Bool("x", false, "select only matches that exactly match the whole line")
+	w := flag.Bool("w", false, "select only matches that form whole words")
+	n := flag.Bool("n", false, "prefix each output line with its line number")
+	b := flag.Bool("b", false, "prefix each output line with its byte offset")
+	o := flag.Bool("o", false, "print only the matched parts of lines")
+	color := colorFlag("never")
+	flag.Var(&color, "color", "highlight matches: auto, always or never, without a value auto")
+	flag.Var(&color, "colour", "same as --color")
+	r := flag.Bool("r", false, "search directories recursively")
+	H := flag.Bool("H", false, "print the file name for each match")
+	h := flag.Bool("h", false, "suppress the file name prefix on output")
+	l := flag.Bool("l", false, "print only names of files with matches")
+	L := flag.Bool("L", false, "print only names of files without matches")
+	q := flag.Bool("q", false, "print nothing, exit with zero status on the first match")
+	s := flag.Bool("s", false, "suppress error messages about nonexistent or unreadable files")
+	m := flag.Int("m", -1, "stop reading a file after NUM selected lines, negative - no limit")
+	var patterns, patternFiles listFlag
+	flag.Var(&patterns, "e", "use PATTERN for matching, may be repeated")
+	flag.Var(&patternFiles, "f", "take patterns from FILE, one per line, may be repeated")
+	var include, exclude globsFlag
+	flag.Var(&include, "include", "search only files whose base name matches GLOB, may be repeated")
+	flag.Var(&exclude, "exclude", "skip files whose base name matches GLOB, may be repeated")
+	parallel := flag.Int("parallel", DefaultParallel(), "search up to N files concurrently")
+	binaryFiles := binaryFilesFlag(binaryMatches)
+	flag.Var(&binaryFiles, "binary-files", "how to search binary files: binary, text or without-match")
+	z := flag.Bool("z", false, "search inside .gz and .bz2 files")
+
+	// значения можно писать слитно с флагом, как в GNU grep: -A2
+	if err := flag.CommandLine.Parse(splitShortArgs(os.Args[1:], "ABCefm")); err != nil {
+		usageError("grep: %s", err.Error())
+	}
 
-	flag.Parse()
+	// явно заданные -A и -B важнее -C, как в GNU grep
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
 
-	ca.FlgColl = make(map[string]interface{}, 8)
+	if !set["A"] {
+		*A = *C
+	}
+	if !set["B"] {
+		*B = *C
+	}
+
+	if *A < 0 || *B < 0 {
+		usageError("grep: %d: invalid context length argument", min(*A, *B))
+	}
+
+	// синтаксис искомых значений задаётся только одним флагом
+	matchers := 0
+	for _, set := range []bool{*F, *G, *E, *P} {
+		if set {
+			matchers++
+		}
+	}
+	if matchers > 1 {
+		usageError("grep: conflicting matchers specified")
+	}
+
+	if *parallel < 1 {
+		usageError("grep: invalid number of parallel searches %d", *parallel)
+	}
+
+	ca.FlgColl = make(map[string]interface{}, 31)
 	ca.FlgColl["-A"] = *A
 	ca.FlgColl["-B"] = *B
 	ca.FlgColl["-C"] = *C
@@ -87,275 +135,486 @@ func (ca *ConsoleArgs) ParseFlags() {
 	ca.FlgColl["-i"] = *i
 	ca.FlgColl["-v"] = *v
 	ca.FlgColl["-F"] = *F
+	ca.FlgColl["-G"] = *G
+	ca.FlgColl["-E"] = *E
+	ca.FlgColl["-P"] = *P
+	ca.FlgColl["-x"] = *x
+	ca.FlgColl["-w"] = *w
+	ca.FlgColl["-e"] = []string(patterns)
+	ca.FlgColl["-f"] = []string(patternFiles)
 	ca.FlgColl["-n"] = *n
+	ca.FlgColl["-b"] = *b
+	ca.FlgColl["-o"] = *o
+	ca.FlgColl["--color"] = color.enabled(os.Stdout)
+	ca.FlgColl["-r"] = *r
+	ca.FlgColl["-H"] = *H
+	ca.FlgColl["-h"] = *h
+	ca.FlgColl["-l"] = *l
+	ca.FlgColl["-L"] = *L
+	ca.FlgColl["-q"] = *q
+	ca.FlgColl["-s"] = *s
+	ca.FlgColl["-m"] = *m
+	ca.FlgColl["--include"] = []string(include)
+	ca.FlgColl["--exclude"] = []string(exclude)
+	ca.FlgColl["--parallel"] = *parallel
+	ca.FlgColl["--binary-files"] = string(binaryFiles)
+	ca.FlgColl["-z"] = *z
 }
 
-/*
-Collect -  метод для получения значения после найденого
-col []string - все строки из файла
-matched []string - строки равные искомому
-keys []int - номера строк в файле
-*/
-func (colA *CollectAfter) Collect(col []string, matched []string, keys []int) ([]string, []int) {
-	after, okA := colA.ca.FlgColl["-A"].(int)
+// usageError - выводит ошибку в аргументах и завершает программу с кодом 2, как в GNU grep
+func usageError(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(2)
+}
 
-	if !okA || after <= 0 || len(matched) == 0 {
-		return matched, keys
-	}
+// listFlag - значения флага, который можно указывать несколько раз, например -e
+type listFlag []string
 
-	return addAfter(col, matched, keys, after)
+func (lf *listFlag) String() string {
+	return strings.Join(*lf, " ")
 }
 
-/*
-Collect - метод для получения значения до найденого
-col []string - все строки из файла
-matched []string - строки равные искомому
-keys []int - номера строк в файле
-*/
-func (colB *CollectBefore) Collect(col []string, matched []string, keys []int) ([]string, []int) {
-	before, okB := colB.ca.FlgColl["-B"].(int)
+func (lf *listFlag) Set(s string) error {
+	*lf = append(*lf, s)
+	return nil
+}
 
-	if !okB || before <= 0 || len(matched) == 0 {
-		return matched, keys
-	}
+// colorFlag - значение --color: auto, always или never, как в GNU grep флаг можно указать без значения
+type colorFlag string
 
-	return addBefore(col, matched, keys, before)
+func (cf *colorFlag) String() string {
+	return string(*cf)
 }
 
-/*
-Collect - реализация метода получения значений до и после найденого
-col []string - все строки из файла
-matched []string - строки равные искомому
-keys []int - номера строк в файле
-*/
-func (colAB *CollectAB) Collect(col []string, matched []string, keys []int) ([]string, []int) {
-	ab, okB := colAB.ca.FlgColl["-C"].(int)
-
-	if !okB || ab <= 0 || len(matched) == 0 {
-		return matched, keys
+func (cf *colorFlag) Set(s string) error {
+	switch s {
+	case "true", "auto", "tty", "if-tty":
+		*cf = "auto"
+	case "always", "yes", "force":
+		*cf = "always"
+	case "never", "no", "none":
+		*cf = "never"
+	default:
+		return fmt.Errorf("invalid argument %q for --color, valid arguments are auto, always and never", s)
 	}
-	res, resKeys := addBefore(col, matched, keys, ab)
-	res, resKeys = addAfter(col, res, resKeys, ab)
 
-	return res, resKeys
+	return nil
 }
 
-// SetArgs - читаем из консоли путь до файла и искомый элемент
-func (g *Grep) SetArgs() error {
-	args := os.Args
+// IsBoolFlag - --color без значения равен --color=auto
+func (cf *colorFlag) IsBoolFlag() bool {
+	return true
+}
 
-	if len(args) < 3 {
-		return fmt.Errorf("file path is empty")
+// enabled - включена ли подсветка при выводе в out, с auto только если out - терминал
+func (cf colorFlag) enabled(out *os.File) bool {
+	switch cf {
+	case "always":
+		return true
+	case "never":
+		return false
 	}
 
-	if len(args) < 2 {
-		return fmt.Errorf("target and file path is empty")
-	}
+	info, err := out.Stat()
 
-	errRFP := g.readFilePath(args)
+	return err == nil && info.Mode()&os.ModeCharDevice != 0 && os.Getenv("TERM") != "dumb"
+}
 
-	if errRFP != nil {
-		return errRFP
-	}
+// binaryFilesFlag - значение --binary-files: binary, text или without-match
+type binaryFilesFlag string
 
-	g.readTarget(args)
+func (bf *binaryFilesFlag) String() string {
+	return string(*bf)
+}
+
+func (bf *binaryFilesFlag) Set(s string) error {
+	switch s {
+	case binaryMatches, binaryText, binaryWithoutMatch:
+		*bf = binaryFilesFlag(s)
+	default:
+		return fmt.Errorf("invalid argument %q for --binary-files, valid arguments are binary, text and without-match", s)
+	}
 
 	return nil
 }
 
-// Grep - фильтруем строки из файла
-func (g *Grep) Grep() ([]string, []int, error) {
-	lines, errRF := g.readFile()
+// globsFlag - значения флагов --include и --exclude, флаг можно указывать несколько раз
+type globsFlag []string
 
-	if errRF != nil {
-		return nil, nil, errRF
+func (gf *globsFlag) String() string {
+	return strings.Join(*gf, " ")
+}
+
+func (gf *globsFlag) Set(s string) error {
+	if _, err := filepath.Match(s, ""); err != nil {
+		return fmt.Errorf("bad glob %q", s)
 	}
+	*gf = append(*gf, s)
+
+	return nil
+}
 
-	// слайсы: результирующий и номеров строк в файле
-	res := make([]string, 0, cap(lines))
-	keysRes := make([]int, 0, cap(lines))
+// splitShortArgs - разделяет слитно записанные флаг и значение (-A2 -> -A 2) для флагов из names
+func splitShortArgs(args []string, names string) []string {
+	res := make([]string, 0, len(args))
 
-	var ignCase string
+	for i, arg := range args {
+		if arg == "--" {
+			return append(res, args[i:]...)
+		}
+
+		if len(arg) > 2 && arg[0] == '-' && strings.IndexByte(names, arg[1]) >= 0 && arg[2] != '=' {
+			res = append(res, arg[:2], arg[2:])
+			continue
+		}
 
-	// если установлен флаг игнорируем регистр строки
-	if g.ca.FlgColl["-i"].(bool) {
-		ignCase = "(?i)"
+		res = append(res, arg)
 	}
 
-	for key, line := range lines {
-		matched, err := regexp.Match(ignCase+g.target, []byte(line))
+	return res
+}
 
-		// если установлен флаг проверяем, что вся строка равна искомой
-		if g.ca.FlgColl["-F"].(bool) && g.target == line {
-			res = append(res, line)
-			keysRes = append(keysRes, key)
-		}
+/*
+SetArgs - читаем из консоли искомые значения и пути до файлов
+значения берутся из -e и -f, а без них из первого аргумента, значение с переводами строк - это несколько значений
+без файлов читается стандартный ввод, а с -r - текущий каталог
+значения компилируются сразу, поэтому неверное выражение отклоняется до чтения файлов
+*/
+func (g *Grep) SetArgs() error {
+	args := flag.Args()
+	patterns := g.ca.FlgColl["-e"].([]string)
+	patternFiles := g.ca.FlgColl["-f"].([]string)
 
-		// если установлен флаг сохраняем строки не удовлетворяющие искомой
-		if !matched && g.ca.FlgColl["-v"].(bool) {
-			res = append(res, line)
-			keysRes = append(keysRes, key)
+	if len(patterns) == 0 && len(patternFiles) == 0 {
+		if len(args) == 0 {
+			return fmt.Errorf("target is empty")
 		}
 
-		// если же флагов для условия сравнения нет, проверяем есть ли искомая строка в текущей из файла
-		if err == nil && matched && !g.ca.FlgColl["-v"].(bool) && !g.ca.FlgColl["-F"].(bool) {
-			res = append(res, line)
-			keysRes = append(keysRes, key)
+		patterns, args = args[:1], args[1:]
+	}
+
+	for _, p := range patterns {
+		g.patterns = append(g.patterns, strings.Split(p, "\n")...)
+	}
+
+	for _, path := range patternFiles {
+		filePatterns, err := readPatterns(path)
+
+		if err != nil {
+			return err
 		}
+		g.patterns = append(g.patterns, filePatterns...)
 	}
 
-	// вызываем методы для получения строк до и после, сами методы решат, нужно ли добавлять значения к текущему слайсу
-	res, keysRes = g.colA.Collect(lines, res, keysRes)
-	res, keysRes = g.colB.Collect(lines, res, keysRes)
-	res, keysRes = g.colC.Collect(lines, res, keysRes)
+	g.files = args
 
-	return res, keysRes, nil
-}
+	if len(g.files) == 0 {
+		g.files = []string{"-"}
+
+		if g.ca.FlgColl["-r"].(bool) {
+			g.files = []string{"."}
+			g.implicitDir = true
+		}
+	}
 
-// readTarget - метод для получения искомой строки
-func (g *Grep) readTarget(args []string) {
-	g.target = args[len(args)-2]
+	return g.compile()
 }
 
-// readFilePath - метод для получения пути до файла
-func (g *Grep) readFilePath(args []string) error {
-	path := args[len(args)-1]
+// readPatterns - читает искомые значения для -f, по одному в строке, "-" - стандартный ввод
+func readPatterns(path string) ([]string, error) {
+	var (
+		data []byte
+		err  error
+	)
+
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
 
-	if !g.fileExist(path) {
-		return fmt.Errorf("file %s does not exist", path)
+	if err != nil {
+		return nil, fmt.Errorf("grep: %w", err)
 	}
 
-	g.filePath = args[len(args)-1]
+	text := strings.TrimSuffix(string(data), "\n")
 
-	return nil
+	// пустой файл не содержит ни одного значения, а пустая строка в файле - пустое значение
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	return strings.Split(text, "\n"), nil
 }
 
-// readFile - метод для получения строк из файла
-func (g *Grep) readFile() ([]string, error) {
-	file, errFile := os.Open(g.filePath)
+/*
+Grep - фильтруем строки из всех файлов и пишем найденные строки и их контекст в w
+ошибки чтения отдельных файлов выводятся в errOut и не прерывают поиск в остальных, с -q поиск
+заканчивается на первом совпадении
+возвращает число найденных строк, с -l, -L и -q в каждом файле учитывается только первая
+*/
+func (g *Grep) Grep(w io.Writer) (int, error) {
+	if g.m == nil {
+		if err := g.compile(); err != nil {
+			return 0, err
+		}
+	}
 
-	defer func(file *os.File) {
-		if errClose := file.Close(); errClose != nil {
-			log.Fatalf("Can not close file")
+	bw := bufio.NewWriter(w)
+	withName := g.withNames()
+
+	// несколько файлов ищутся одновременно, иначе горутины делят между собой строки одного файла
+	if g.workers > 1 && (len(g.files) > 1 || g.ca.FlgColl["-r"].(bool)) {
+		total, err := g.grepFiles(bw, withName)
+		if err != nil {
+			return total, err
 		}
-	}(file)
 
-	if errFile != nil {
-		return nil, fmt.Errorf("file %s does not exist", file.Name())
+		return total, bw.Flush()
 	}
 
-	lines := make([]string, 0)
-	scanner := bufio.NewScanner(file)
+	out := newPrinter(bw, g.ca, g.m, withName)
+	total := 0
+
+	quiet := g.ca.FlgColl["-q"].(bool)
 
-	success := scanner.Scan()
+	for _, path := range g.files {
+		err := g.eachFile(path, func(name string) error {
+			n, err := g.searchInput(name, out)
+			total += n
+
+			if err == nil && quiet && total > 0 {
+				return errStopped
+			}
 
-	for success {
-		lines = append(lines, scanner.Text())
-		success = scanner.Scan()
+			return err
+		})
+
+		if errors.Is(err, errStopped) {
+			break
+		}
+		if err != nil {
+			return total, err
+		}
 	}
 
-	return lines, nil
+	return total, bw.Flush()
 }
 
-// fileExist - метод для проверки существования файла
-func (g *Grep) fileExist(path string) bool {
-	_, err := os.Stat(path)
+/*
+exitCode - код завершения как в GNU grep: 0 - строки найдены, 1 - не найдены, 2 - были ошибки файлов,
+с -q найденная строка важнее ошибок
+*/
+func (g *Grep) exitCode(matches int) int {
+	switch {
+	case matches > 0 && g.ca.FlgColl["-q"].(bool):
+		return 0
+	case g.failed.Load():
+		return 2
+	case matches > 0:
+		return 0
+	}
 
-	if errors.Is(err, os.ErrNotExist) || err != nil {
+	return 1
+}
+
+/*
+withNames - выводить ли имя файла перед строками: явно с -H и -h, иначе при нескольких файлах
+или с -r, если единственный путь - каталог
+*/
+func (g *Grep) withNames() bool {
+	switch {
+	case g.ca.FlgColl["-H"].(bool):
+		return true
+	case g.ca.FlgColl["-h"].(bool):
+		return false
+	case len(g.files) > 1:
+		return true
+	case !g.ca.FlgColl["-r"].(bool) || len(g.files) == 0:
 		return false
 	}
 
-	return true
-}
+	info, err := os.Stat(g.files[0])
 
-func main() {
-	ca := &ConsoleArgs{}
-	ca.ParseFlags()
+	return err == nil && info.IsDir()
+}
 
-	grep := NewGrep(ca)
-	errArgs := grep.SetArgs()
+// compile - метод создающий поиск искомых значений один раз перед поиском
+func (g *Grep) compile() error {
+	m, err := newMatcher(g.patterns, g.ca.FlgColl)
 
-	if errArgs != nil {
-		fmt.Println(errArgs.Error())
-		return
+	if err != nil {
+		return fmt.Errorf("grep: %w", err)
 	}
+	g.m = m
 
-	res, keys, errGrep := grep.Grep()
+	return nil
+}
 
-	if errGrep != nil {
-		fmt.Println(errGrep.Error())
-		return
+/*
+search - метод фильтрующий r частями по chunkSize байт, строки до совпадения для -B хранятся
+в кольцевом буфере, так что файл не читается в память целиком
+name string - имя файла для вывода
+с -l и -L вместо строк выводится имя файла, с -c число найденных строк, с -q ничего не выводится
+и поиск заканчивается на первой найденной строке, с -m после NUM найденных строк файл дальше не читается
+в двоичном файле вместо строк выводится сообщение о первом совпадении, как задано --binary-files
+возвращает число найденных строк, с -l, -L и -q не больше одной
+*/
+func (g *Grep) search(r io.Reader, name string, out *printer) (int, error) {
+	out.startFile(name)
+	beforeLen := g.ca.FlgColl["-B"].(int)
+	before := newLineRing(beforeLen)
+	listMatched, listUnmatched := g.ca.FlgColl["-l"].(bool), g.ca.FlgColl["-L"].(bool)
+	quiet, maxCount := g.ca.FlgColl["-q"].(bool), g.ca.FlgColl["-m"].(int)
+	counting := g.ca.FlgColl["-c"].(bool) && !listMatched && !listUnmatched && !quiet
+	count := counting || listMatched || listUnmatched || quiet
+
+	br := bufio.NewReaderSize(r, binaryBlock)
+	binary := isBinary(br)
+	binaryFiles := g.ca.FlgColl["--binary-files"].(string)
+
+	// с without-match в двоичном файле нет совпадений, но -c и -L о нём всё равно выводят
+	if binary && binaryFiles == binaryWithoutMatch {
+		return g.searchEnd(out, 0, counting, listUnmatched)
 	}
+	binaryMessage := binary && binaryFiles == binaryMatches && !count
+
+	// сколько строк после совпадения ещё нужно вывести
+	afterLeft := 0
+	matches := 0
+	num := 0
+	var offset int64
+
+	chunks := newChunkReader(br)
+
+scan:
+	for {
+		lines, errRead := chunks.next()
+		matched := g.matchLines(lines)
+
+		for i, text := range lines {
+			line := numberedLine{num: num + i, offset: offset}
+			offset += int64(len(text)) + 1
+
+			// после NUM найденных строк выводятся только строки контекста после последней из них
+			if maxCount >= 0 && matches >= maxCount {
+				if afterLeft == 0 || count {
+					break scan
+				}
+
+				line.text = string(text)
+				if err := out.print(line, false); err != nil {
+					return matches, err
+				}
+				afterLeft--
+
+				continue
+			}
 
-	if len(res) > 0 && len(keys) > 0 {
-		if ca.FlgColl["-n"].(bool) {
-			for _, key := range keys {
-				fmt.Println(key)
+			if matched[i] {
+				matches++
+
+				// для -q, -l и -L достаточно первого совпадения
+				if quiet {
+					return 1, nil
+				}
+				if listMatched {
+					return 1, out.name()
+				}
+				if listUnmatched {
+					return 1, nil
+				}
+
+				// с -c нужно только число совпадений
+				if count {
+					continue
+				}
+
+				// строки двоичного файла не выводятся, достаточно первого совпадения
+				if binaryMessage {
+					return matches, out.binaryMatches()
+				}
+
+				for _, prev := range before.drain() {
+					if err := out.print(prev, false); err != nil {
+						return matches, err
+					}
+				}
+
+				line.text = string(text)
+				if err := out.print(line, true); err != nil {
+					return matches, err
+				}
+				afterLeft = g.ca.FlgColl["-A"].(int)
+
+				continue
+			}
+
+			if afterLeft > 0 && !count {
+				line.text = string(text)
+				if err := out.print(line, false); err != nil {
+					return matches, err
+				}
+				afterLeft--
+
+				continue
+			}
+
+			if beforeLen > 0 && !count {
+				line.text = string(text)
+				before.push(line)
 			}
-			return
 		}
-		if ca.FlgColl["-c"].(bool) {
-			fmt.Println(len(res))
-			return
+		num += len(lines)
+
+		if errRead == io.EOF {
+			break
 		}
-		for _, line := range res {
-			fmt.Println(line)
+		if errRead != nil {
+			g.reportError(name, errRead)
+			break
 		}
-		return
 	}
 
-	fmt.Println("empty")
-}
-
-// Collector - интерфейс для структур которые могут отдавать найденые значения + новые
-type Collector interface {
-	Collect(col []string, matched []string, keys []int) ([]string, []int)
+	return g.searchEnd(out, matches, counting, listUnmatched)
 }
 
-/*
-addAfter - функция для получения элементов из слайса до найденого
-col []string - все строки из файла
-matched []string - строки равные искомому
-keys []int - номера строк в файле
-*/
-func addAfter(col []string, matched []string, keys []int, after int) ([]string, []int) {
-	lastKey := keys[len(keys)-1]
+// searchEnd - метод завершающий поиск в файле: с -c выводит число найденных строк, с -L имя файла без совпадений
+func (g *Grep) searchEnd(out *printer, matches int, counting, listUnmatched bool) (int, error) {
+	if counting {
+		return matches, out.count(matches)
+	}
 
-	for i := lastKey + 1; i <= lastKey+after; i++ {
-		if i >= len(col) {
-			break
-		}
-		matched = append(matched, col[i])
-		keys = append(keys, i)
+	if listUnmatched {
+		return 0, out.name()
 	}
 
-	return matched, keys
+	return matches, nil
 }
 
-/*
-addBefore - функция для получения элементов из слайса до найденого
-col []string - все строки из файла
-matched []string - строки равные искомому
-keys []int - номера строк в файле
-*/
-func addBefore(col []string, matched []string, keys []int, before int) ([]string, []int) {
-	firstKey := keys[0]
-	newRes := make([]string, 0)
-	newKeys := make([]int, 0)
+// match - метод проверяющий, подходит ли строка под условия поиска
+func (g *Grep) match(line []byte) bool {
+	// если установлен флаг сохраняем строки не удовлетворяющие искомой
+	return g.m.Match(line) != g.ca.FlgColl["-v"].(bool)
+}
 
-	for i := firstKey - before; i < firstKey; i++ {
-		if i < 0 {
-			break
-		}
-		newRes = append(newRes, col[i])
-		newKeys = append(newKeys, i)
+func main() {
+	ca := &ConsoleArgs{}
+	ca.ParseFlags()
+
+	grep := NewGrep(ca)
+	errArgs := grep.SetArgs()
+
+	if errArgs != nil {
+		usageError("%s", errArgs.Error())
 	}
 
-	if len(newRes) > 0 {
-		newRes = append(newRes, matched...)
-		newKeys = append(newKeys, keys...)
-		return newRes, newKeys
+	matches, errGrep := grep.Grep(os.Stdout)
+
+	if errGrep != nil {
+		fmt.Fprintln(os.Stderr, errGrep.Error())
+		os.Exit(2)
 	}
 
-	return matched, keys
+	os.Exit(grep.exitCode(matches))
 }
@@ -0,0 +1,1432 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func newTestGrep(target string, flags map[string]interface{}) *Grep {
+	ca := &ConsoleArgs{FlgColl: map[string]interface{}{
+		"-A":             0,
+		"-B":             0,
+		"-C":             0,
+		"-c":             false,
+		"-i":             false,
+		"-v":             false,
+		"-F":             false,
+		"-G":             false,
+		"-E":             false,
+		"-P":             false,
+		"-x":             false,
+		"-w":             false,
+		"-e":             []string(nil),
+		"-f":             []string(nil),
+		"-n":             false,
+		"-b":             false,
+		"-o":             false,
+		"--color":        false,
+		"-r":             false,
+		"-H":             false,
+		"-h":             false,
+		"-l":             false,
+		"-L":             false,
+		"-q":             false,
+		"-s":             false,
+		"-m":             -1,
+		"--include":      []string(nil),
+		"--exclude":      []string(nil),
+		"--parallel":     1,
+		"--binary-files": binaryMatches,
+		"-z":             false,
+	}}
+
+	for name, val := range flags {
+		ca.FlgColl[name] = val
+	}
+
+	grep := NewGrep(ca)
+	grep.patterns = strings.Split(target, "\n")
+
+	return grep
+}
+
+// searchString - поиск в одной строке input без имени файла
+func searchString(g *Grep, input string) (string, int, error) {
+	if err := g.compile(); err != nil {
+		return "", 0, err
+	}
+
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+
+	matches, err := g.search(strings.NewReader(input), "", newPrinter(bw, g.ca, g.m, false))
+	bw.Flush()
+
+	return buf.String(), matches, err
+}
+
+// ожидаемые результаты получены с помощью GNU grep
+func TestContext(t *testing.T) {
+	input := strings.Join([]string{"a1", "b", "c", "a2", "d", "e", "f", "g", "a3", "h", "a4"}, "\n")
+
+	tsts := []struct {
+		name  string
+		flags map[string]interface{}
+		want  []string
+	}{
+		{
+			name:  "no context",
+			flags: map[string]interface{}{},
+			want:  []string{"a1", "a2", "a3", "a4"},
+		},
+		{
+			name:  "after",
+			flags: map[string]interface{}{"-A": 1},
+			want:  []string{"a1", "b", "--", "a2", "d", "--", "a3", "h", "a4"},
+		},
+		{
+			name:  "before",
+			flags: map[string]interface{}{"-B": 2},
+			want:  []string{"a1", "b", "c", "a2", "--", "f", "g", "a3", "h", "a4"},
+		},
+		{
+			name:  "around",
+			flags: map[string]interface{}{"-A": 1, "-B": 1},
+			want:  []string{"a1", "b", "c", "a2", "d", "--", "g", "a3", "h", "a4"},
+		},
+		{
+			name:  "overlapping windows are merged",
+			flags: map[string]interface{}{"-A": 3, "-B": 1},
+			want:  []string{"a1", "b", "c", "a2", "d", "e", "f", "g", "a3", "h", "a4"},
+		},
+		{
+			name:  "invert",
+			flags: map[string]interface{}{"-v": true, "-A": 1},
+			want:  []string{"b", "c", "a2", "d", "e", "f", "g", "a3", "h", "a4"},
+		},
+		{
+			name:  "line numbers",
+			flags: map[string]interface{}{"-n": true, "-B": 1},
+			want:  []string{"1:a1", "--", "3-c", "4:a2", "--", "8-g", "9:a3", "10-h", "11:a4"},
+		},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := searchString(newTestGrep("a", tt.flags), input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if want := strings.Join(tt.want, "\n") + "\n"; got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+// ожидаемые результаты получены с помощью GNU grep
+func TestMatchModes(t *testing.T) {
+	input := strings.Join([]string{
+		"cat", "a cat sat", "concatenate", "cat_food", "Cat", "cat-like", "a.b", "axb", "",
+		"foo bar", "bar", "кот", "котик", "ab+",
+	}, "\n")
+
+	tsts := []struct {
+		name     string
+		patterns []string
+		flags    map[string]interface{}
+		want     []string
+	}{
+		{
+			name:     "fixed string",
+			patterns: []string{"cat"},
+			flags:    map[string]interface{}{"-F": true},
+			want:     []string{"cat", "a cat sat", "concatenate", "cat_food", "cat-like"},
+		},
+		{
+			name:     "fixed string is not a regexp",
+			patterns: []string{"a.b"},
+			flags:    map[string]interface{}{"-F": true},
+			want:     []string{"a.b"},
+		},
+		{
+			name:     "regexp",
+			patterns: []string{"a.b"},
+			flags:    map[string]interface{}{},
+			want:     []string{"a.b", "axb"},
+		},
+		{
+			name:     "fixed strings",
+			patterns: []string{"foo", "b+", "zzz"},
+			flags:    map[string]interface{}{"-F": true},
+			want:     []string{"cat_food", "foo bar", "ab+"},
+		},
+		{
+			name:     "empty fixed string matches every line",
+			patterns: []string{""},
+			flags:    map[string]interface{}{"-F": true, "-c": true},
+			want:     []string{"14"},
+		},
+		{
+			name:     "fixed string ignoring case",
+			patterns: []string{"CAT"},
+			flags:    map[string]interface{}{"-F": true, "-i": true},
+			want:     []string{"cat", "a cat sat", "concatenate", "cat_food", "Cat", "cat-like"},
+		},
+		{
+			name:     "whole line",
+			patterns: []string{"cat"},
+			flags:    map[string]interface{}{"-x": true},
+			want:     []string{"cat"},
+		},
+		{
+			name:     "whole line fixed strings",
+			patterns: []string{"cat", "bar", "a.b"},
+			flags:    map[string]interface{}{"-x": true, "-F": true},
+			want:     []string{"cat", "a.b", "bar"},
+		},
+		{
+			name:     "empty whole line",
+			patterns: []string{""},
+			flags:    map[string]interface{}{"-x": true, "-n": true},
+			want:     []string{"9:"},
+		},
+		{
+			name:     "whole word",
+			patterns: []string{"cat"},
+			flags:    map[string]interface{}{"-w": true},
+			want:     []string{"cat", "a cat sat", "cat-like"},
+		},
+		{
+			name:     "whole word ignoring case",
+			patterns: []string{"cat"},
+			flags:    map[string]interface{}{"-w": true, "-i": true},
+			want:     []string{"cat", "a cat sat", "Cat", "cat-like"},
+		},
+		{
+			name:     "whole word fixed string",
+			patterns: []string{"CAT"},
+			flags:    map[string]interface{}{"-w": true, "-F": true, "-i": true},
+			want:     []string{"cat", "a cat sat", "Cat", "cat-like"},
+		},
+		{
+			name:     "whole word alternation",
+			patterns: []string{`cat\|bar`},
+			flags:    map[string]interface{}{"-w": true},
+			want:     []string{"cat", "a cat sat", "cat-like", "foo bar", "bar"},
+		},
+		{
+			name:     "whole cyrillic word",
+			patterns: []string{"кот"},
+			flags:    map[string]interface{}{"-w": true},
+			want:     []string{"кот"},
+		},
+		{
+			name:     "whole line is stronger than whole word",
+			patterns: []string{"cat"},
+			flags:    map[string]interface{}{"-w": true, "-x": true},
+			want:     []string{"cat"},
+		},
+		{
+			name:     "several regexps",
+			patterns: []string{"cat$", "^bar"},
+			flags:    map[string]interface{}{},
+			want:     []string{"cat", "bar"},
+		},
+		{
+			name:     "word start and end in cyrillic",
+			patterns: []string{`\<кот\>`},
+			flags:    map[string]interface{}{},
+			want:     []string{"кот"},
+		},
+		{
+			name:     "not a word boundary in cyrillic",
+			patterns: []string{`\Bтик`},
+			flags:    map[string]interface{}{},
+			want:     []string{"котик"},
+		},
+		{
+			name:     "word boundary",
+			patterns: []string{`\bcat\b`},
+			flags:    map[string]interface{}{},
+			want:     []string{"cat", "a cat sat", "cat-like"},
+		},
+		{
+			name:     "no patterns",
+			patterns: nil,
+			flags:    map[string]interface{}{"-c": true},
+			want:     []string{"0"},
+		},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGrep("", tt.flags)
+			g.patterns = tt.patterns
+
+			got, matches, err := searchString(g, input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// с -c строки не выводятся, поэтому сравнивается число совпадений
+			if tt.flags["-c"] == true {
+				got = fmt.Sprintf("%d\n", matches)
+			}
+
+			if want := strings.Join(tt.want, "\n") + "\n"; got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestReadPatterns(t *testing.T) {
+	dir := t.TempDir()
+
+	tsts := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{name: "empty file", data: "", want: nil},
+		{name: "one per line", data: "cat\ndog\n", want: []string{"cat", "dog"}},
+		{name: "no final newline", data: "cat\ndog", want: []string{"cat", "dog"}},
+		{name: "empty line is a pattern", data: "\n", want: []string{""}},
+	}
+
+	for i, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, fmt.Sprintf("patterns%d", i))
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := readPatterns(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) || len(got) != len(tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := readPatterns(filepath.Join(dir, "missing")); err == nil {
+		t.Error("no error for a missing file")
+	}
+}
+
+func TestTranslatePOSIX(t *testing.T) {
+	tsts := []struct {
+		name     string
+		pattern  string
+		extended bool
+		want     string
+		wantErr  bool
+	}{
+		{name: "basic operators are literal", pattern: "a+b?(c)|{d}", want: `a\+b\?\(c\)\|\{d\}`},
+		{name: "basic escaped operators", pattern: `\(a\|b\)\+c\?`, want: "(a|b)+c?"},
+		{name: "basic interval", pattern: `a\{2,3\}`, want: "a{2,3}"},
+		{name: "basic unmatched interval", pattern: `a\{2`, wantErr: true},
+		{name: "extended operators", pattern: "(a|b)+c?d{2,}", extended: true, want: "(a|b)+c?d{2,}"},
+		{name: "extended escaped operators", pattern: `\(a\|b\)\+`, extended: true, want: `\(a\|b\)\+`},
+		{name: "extended interval without minimum", pattern: "a{,3}", extended: true, want: "a{0,3}"},
+		{name: "extended brace without interval", pattern: "a{x", extended: true, want: `a\{x`},
+		{name: "star at start", pattern: `*a\(*b\|*c\)`, want: `\*a(\*b|\*c)`},
+		{name: "star after anchor", pattern: "^*a", want: `^\*a`},
+		{name: "extended repetition at start", pattern: "+a|?b(*c)", extended: true, want: "a|b(c)"},
+		{name: "extended star after anchor", pattern: "^*", extended: true, want: "(?:^)*"},
+		{name: "extended anchor escapes repeated", pattern: "\\`+a$?", extended: true, want: `(?:\A)+a(?:$)?`},
+		{name: "stacked interval", pattern: "a{2}{1}", extended: true, want: "(?:a{2}){1}"},
+		{name: "stacked stars", pattern: "a**", extended: true, want: "(?:a*)*"},
+		{name: "basic stacked stars", pattern: "a**", want: "(?:a*)*"},
+		{name: "stacked plus and question", pattern: "a+?", extended: true, want: "(?:a+)?"},
+		{name: "stacked repetition of group", pattern: "(ab)*+", extended: true, want: "(?:(ab)*)+"},
+		{name: "stacked repetition of multibyte rune", pattern: "я*+", extended: true, want: "(?:я*)+"},
+		{name: "basic anchors in the middle", pattern: "a^b$c", want: `a\^b\$c`},
+		{name: "basic anchors in groups", pattern: `\(^a$\|^b$\)`, want: "(^a$|^b$)"},
+		{name: "extended anchors", pattern: "a^b$c", extended: true, want: "a^b$c"},
+		{name: "bracket with backslash", pattern: `[\]`, want: `[\\]`},
+		{name: "bracket with closing bracket", pattern: "[]a]", want: `[\]a]`},
+		{name: "negated bracket with closing bracket", pattern: "[^]a]", want: `[^\]a]`},
+		{name: "bracket class", pattern: "[[:alpha:]_]", want: "[[:alpha:]_]"},
+		{name: "bracket equivalence class", pattern: "[[=a=][.-.]]", want: `[a\-]`},
+		{name: "unmatched bracket", pattern: "[abc", wantErr: true},
+		{name: "word boundaries", pattern: `\<a\>\b\B`, want: `(?P<grepWordStart>)a(?P<grepWordEnd>)(?P<grepBoundary>)(?P<grepNotBoundary>)`},
+		{name: "word characters", pattern: `\w\W`, want: `[\pL\pN_][^\pL\pN_]`},
+		{name: "escaped literals", pattern: `\.\*\[\й`, want: `\.\*\[й`},
+		{name: "backreference", pattern: `\(a\)\1`, wantErr: true},
+		{name: "unmatched group", pattern: "(a", extended: true, wantErr: true},
+		{name: "extended unmatched closing group is literal", pattern: "(a))", extended: true, want: `(a)\)`},
+		{name: "basic unmatched closing group", pattern: `a\)`, wantErr: true},
+		{name: "trailing backslash", pattern: `a\`, wantErr: true},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := translatePOSIX(tt.pattern, tt.extended)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("translatePOSIX(%q) = %q, want %q", tt.pattern, got, tt.want)
+			}
+			if err == nil {
+				if _, errCompile := regexp.Compile(got); errCompile != nil {
+					t.Errorf("result does not compile: %v", errCompile)
+				}
+			}
+		})
+	}
+}
+
+func TestPatternErrors(t *testing.T) {
+	tsts := []struct {
+		name     string
+		patterns []string
+		flags    map[string]interface{}
+		backref  bool
+	}{
+		{name: "basic backreference", patterns: []string{`\(a\)\1`}, flags: map[string]interface{}{}, backref: true},
+		{name: "extended backreference", patterns: []string{`(a)\2`}, flags: map[string]interface{}{"-E": true}, backref: true},
+		{name: "perl backreference", patterns: []string{`(a)\1`}, flags: map[string]interface{}{"-P": true}, backref: true},
+		{name: "perl lookahead", patterns: []string{"a(?=b)"}, flags: map[string]interface{}{"-P": true}},
+		{name: "invalid second pattern", patterns: []string{"a", "b{2,1}"}, flags: map[string]interface{}{"-E": true}},
+		{name: "invalid perl pattern", patterns: []string{"(a"}, flags: map[string]interface{}{"-P": true}},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGrep("", tt.flags)
+			g.patterns = tt.patterns
+
+			err := g.compile()
+			if err == nil {
+				t.Fatal("no error for an invalid pattern")
+			}
+			if errors.Is(err, errBackref) != tt.backref {
+				t.Errorf("err = %v, backreference error %v", err, tt.backref)
+			}
+		})
+	}
+
+	// экранированный '\' перед цифрой - не обратная ссылка
+	g := newTestGrep("", map[string]interface{}{"-P": true})
+	g.patterns = []string{`a\\1`}
+	if err := g.compile(); err != nil {
+		t.Errorf("escaped backslash: %v", err)
+	}
+}
+
+// containsAny - проверка Ахо - Корасик простым перебором строк
+func containsAny(line string, patterns []string) bool {
+	for _, p := range patterns {
+		if strings.Contains(line, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func FuzzAhoCorasick(f *testing.F) {
+	f.Add("he\nshe\nhis\nhers", "ushers")
+	f.Add("abcd\nbc\nbcx", "abcx")
+	f.Add("aab\nab\nb", "aaaa")
+	f.Add("\nx", "")
+
+	f.Fuzz(func(t *testing.T, patterns, line string) {
+		list := strings.Split(patterns, "\n")
+
+		ac := newAhoCorasick(list)
+
+		if got, want := ac.Match([]byte(line)), containsAny(line, list); got != want {
+			t.Errorf("Match(%q) with %q = %v, want %v", line, list, got, want)
+		}
+
+		// каждое найденное вхождение - одна из строк, и вхождения идут слева направо без пересечений
+		prev := 0
+		for _, loc := range ac.FindAllIndex([]byte(line), -1) {
+			if loc[0] < prev || !slices.Contains(list, line[loc[0]:loc[1]]) {
+				t.Fatalf("FindAllIndex(%q) with %q returned %v", line, list, loc)
+			}
+			prev = loc[1]
+		}
+	})
+}
+
+func BenchmarkFixedStrings(b *testing.B) {
+	input := []byte(testLines(50000))
+	patterns := make([]string, 100)
+
+	for i := range patterns {
+		patterns[i] = fmt.Sprintf("word%d", i)
+	}
+	patterns[len(patterns)-1] = "mouse"
+
+	re := regexp.MustCompile(strings.Join(patterns, "|"))
+	ac := newAhoCorasick(patterns)
+	lines := bytes.Split(input, []byte("\n"))
+
+	for _, bm := range []struct {
+		name string
+		m    matcher
+	}{{"regexp", re}, {"aho-corasick", ac}} {
+		b.Run(bm.name, func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+			for i := 0; i < b.N; i++ {
+				for _, line := range lines {
+					bm.m.Match(line)
+				}
+			}
+		})
+	}
+}
+
+// ожидаемые результаты получены с помощью GNU grep
+func TestOutput(t *testing.T) {
+	input := strings.Join([]string{"a mouse and a mouse", "cat", "dog", "mouse", "x", "y", "z", "mice mouse"}, "\n")
+
+	// color - подсветка s цветом sgr, как в GNU grep
+	color := func(s, sgr string) string {
+		return "\x1b[" + sgr + "m\x1b[K" + s + "\x1b[m\x1b[K"
+	}
+
+	tsts := []struct {
+		name   string
+		target string
+		flags  map[string]interface{}
+		want   []string
+	}{
+		{
+			name:   "line numbers and byte offsets",
+			target: "cat",
+			flags:  map[string]interface{}{"-n": true, "-b": true, "-A": 1},
+			want:   []string{"2:20:cat", "3-24-dog"},
+		},
+		{
+			name:   "only matching",
+			target: "mouse",
+			flags:  map[string]interface{}{"-o": true, "-n": true, "-b": true},
+			want:   []string{"1:2:mouse", "1:14:mouse", "4:28:mouse", "8:45:mouse"},
+		},
+		{
+			name:   "only matching with context",
+			target: "mouse",
+			flags:  map[string]interface{}{"-o": true, "-n": true, "-A": 1, "-B": 1},
+			want:   []string{"1:mouse", "1:mouse", "4:mouse", "--", "8:mouse"},
+		},
+		{
+			name:   "only matching inverted",
+			target: "mouse",
+			flags:  map[string]interface{}{"-o": true, "-v": true, "-n": true, "-A": 1},
+			want:   []string{"4-mouse", "8-mouse"},
+		},
+		{
+			name:   "color",
+			target: "mouse",
+			flags:  map[string]interface{}{"--color": true, "-n": true, "-A": 1},
+			want: []string{
+				color("1", "32") + color(":", "36") + "a " + color("mouse", "01;31") + " and a " + color("mouse", "01;31"),
+				color("2", "32") + color("-", "36") + "cat",
+				color("--", "36"),
+				color("4", "32") + color(":", "36") + color("mouse", "01;31"),
+				color("5", "32") + color("-", "36") + "x",
+				color("--", "36"),
+				color("8", "32") + color(":", "36") + "mice " + color("mouse", "01;31"),
+			},
+		},
+		{
+			name:   "color of context lines before with invert",
+			target: "mouse",
+			flags:  map[string]interface{}{"--color": true, "-v": true, "-B": 1},
+			want:   []string{"a " + color("mouse", "01;31") + " and a " + color("mouse", "01;31"), "cat", "dog", color("mouse", "01;31"), "x", "y", "z"},
+		},
+		{
+			name:   "color of context lines with invert",
+			target: "mouse",
+			flags:  map[string]interface{}{"--color": true, "-v": true, "-A": 1},
+			want:   []string{"cat", "dog", color("mouse", "01;31"), "x", "y", "z", "mice " + color("mouse", "01;31")},
+		},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := searchString(newTestGrep(tt.target, tt.flags), input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if want := strings.Join(tt.want, "\n") + "\n"; got != want {
+				t.Errorf("got:\n%q\nwant:\n%q", got, want)
+			}
+		})
+	}
+}
+
+// ожидаемые результаты получены с помощью GNU grep
+func TestFindAllIndex(t *testing.T) {
+	line := "cat cat concat_cat-cat cats"
+
+	tsts := []struct {
+		name     string
+		patterns []string
+		flags    map[string]interface{}
+		want     []string
+	}{
+		{
+			name:     "regexp",
+			patterns: []string{"c?at"},
+			flags:    map[string]interface{}{"-E": true},
+			want:     []string{"cat", "cat", "cat", "cat", "cat", "cat"},
+		},
+		{
+			name:     "longest of regexps",
+			patterns: []string{"cat", "concat_cat"},
+			flags:    map[string]interface{}{},
+			want:     []string{"cat", "cat", "concat_cat", "cat", "cat"},
+		},
+		{
+			name:     "longest of fixed strings",
+			patterns: []string{"ca", "cat", "at", "t c"},
+			flags:    map[string]interface{}{"-F": true},
+			want:     []string{"cat", "cat", "cat", "cat", "cat", "cat"},
+		},
+		{
+			name:     "fixed string",
+			patterns: []string{"cat"},
+			flags:    map[string]interface{}{"-F": true},
+			want:     []string{"cat", "cat", "cat", "cat", "cat", "cat"},
+		},
+		{
+			name:     "words next to each other",
+			patterns: []string{"cat"},
+			flags:    map[string]interface{}{"-w": true},
+			want:     []string{"cat", "cat", "cat"},
+		},
+		{
+			name:     "whole line",
+			patterns: []string{"cat"},
+			flags:    map[string]interface{}{"-x": true, "-F": true},
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGrep("", tt.flags)
+			g.patterns = tt.patterns
+			if err := g.compile(); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, loc := range g.m.FindAllIndex([]byte(line), -1) {
+				got = append(got, line[loc[0]:loc[1]])
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// ожидаемые результаты получены с помощью GNU grep в локали C.UTF-8
+func TestWordBoundaries(t *testing.T) {
+	line := "кот, котик и скот"
+
+	tsts := []struct {
+		name    string
+		pattern string
+		input   string
+		flags   map[string]interface{}
+		want    []string
+	}{
+		{name: "word start", pattern: `\<кот`, want: []string{"кот", "кот"}},
+		{name: "word end", pattern: `кот\>`, want: []string{"кот", "кот"}},
+		{name: "whole word", pattern: `\<кот\>`, want: []string{"кот"}},
+		{name: "inside word", pattern: `\Bтик`, want: []string{"тик"}},
+		{name: "word characters", pattern: `\<к\w*`, want: []string{"кот", "котик"}},
+		{name: "with -w", pattern: `\<к\w*`, flags: map[string]interface{}{"-w": true}, want: []string{"кот", "котик"}},
+		{name: "alternatives", pattern: `\<\(ко\|ско\)т\>`, flags: map[string]interface{}{"-w": true}, want: []string{"кот", "скот"}},
+		// самое длинное совпадение не проходит проверку границы, но подходит более короткое
+		{name: "shorter match at boundary", pattern: `foo.*\b`, input: "foo bar.", want: []string{"foo bar"}},
+		{name: "shorter match at word end", pattern: `a.*\>`, input: "ab c!", want: []string{"ab c"}},
+		{name: "later start", pattern: `\<.*b`, input: "!b c", want: []string{"b"}},
+		{name: "later start in cyrillic", pattern: `\<я.*\>`, input: "бя я-ю!", want: []string{"я-ю"}},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := map[string]interface{}{"-o": true}
+			for name, val := range tt.flags {
+				flags[name] = val
+			}
+
+			input := line
+			if tt.input != "" {
+				input = tt.input
+			}
+
+			got, _, err := searchString(newTestGrep(tt.pattern, flags), input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if want := strings.Join(tt.want, "\n") + "\n"; got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	input := "mouse is here\ncat not here\nmouse again\ndog"
+
+	got, matches, err := searchString(newTestGrep("mouse", map[string]interface{}{"-c": true, "-C": 1, "-A": 1, "-B": 1}), input)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matches != 2 {
+		t.Errorf("matches = %d, want 2", matches)
+	}
+	if got != "2\n" {
+		t.Errorf("got %q with -c, want only the count", got)
+	}
+}
+
+// ожидаемые результаты получены с помощью GNU grep
+func TestLimits(t *testing.T) {
+	input := strings.Join([]string{"a mouse and a mouse", "cat", "dog", "mouse", "x", "y", "z", "mice mouse"}, "\n")
+
+	tsts := []struct {
+		name    string
+		flags   map[string]interface{}
+		want    []string
+		matches int
+	}{
+		{
+			name:    "max count",
+			flags:   map[string]interface{}{"-m": 2},
+			want:    []string{"a mouse and a mouse", "mouse"},
+			matches: 2,
+		},
+		{
+			name:    "max count keeps trailing context",
+			flags:   map[string]interface{}{"-m": 1, "-A": 3, "-n": true},
+			want:    []string{"1:a mouse and a mouse", "2-cat", "3-dog", "4-mouse"},
+			matches: 1,
+		},
+		{
+			name:    "max count inverted",
+			flags:   map[string]interface{}{"-m": 1, "-A": 1, "-v": true, "-n": true},
+			want:    []string{"2:cat", "3-dog"},
+			matches: 1,
+		},
+		{
+			name:    "max count zero",
+			flags:   map[string]interface{}{"-m": 0},
+			want:    nil,
+			matches: 0,
+		},
+		{
+			name:    "count with max count",
+			flags:   map[string]interface{}{"-m": 2, "-c": true, "-v": true},
+			want:    []string{"2"},
+			matches: 2,
+		},
+		{
+			name:    "quiet",
+			flags:   map[string]interface{}{"-q": true, "-c": true},
+			want:    nil,
+			matches: 1,
+		},
+		{
+			name:    "names instead of count",
+			flags:   map[string]interface{}{"-l": true, "-c": true},
+			want:    []string{""},
+			matches: 1,
+		},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			got, matches, err := searchString(newTestGrep("mouse", tt.flags), input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := ""
+			if len(tt.want) > 0 {
+				want = strings.Join(tt.want, "\n") + "\n"
+			}
+
+			if got != want || matches != tt.matches {
+				t.Errorf("got %d matches:\n%s\nwant %d matches:\n%s", matches, got, tt.matches, want)
+			}
+		})
+	}
+}
+
+func TestLineRing(t *testing.T) {
+	ring := newLineRing(3)
+
+	for i := 0; i < 5; i++ {
+		ring.push(numberedLine{num: i})
+	}
+
+	got := ring.drain()
+	if len(got) != 3 || got[0].num != 2 || got[2].num != 4 {
+		t.Errorf("drain() = %v, want lines 2, 3, 4", got)
+	}
+	if len(ring.drain()) != 0 {
+		t.Error("ring is not empty after drain")
+	}
+}
+
+// ожидаемые результаты получены с помощью GNU grep
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.txt":       "mouse\ncat\ndog\nmouse two\n",
+		"b.log":       "no animals\n",
+		"sub/c.txt":   "cat\nmouse three\n",
+		"sub/d.log":   "mouse four\n",
+		"sub/e/f.txt": "nothing\n",
+	}
+
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tsts := []struct {
+		name    string
+		flags   map[string]interface{}
+		files   []string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:  "single file without name",
+			files: []string{"a.txt"},
+			want:  []string{"mouse", "mouse two"},
+		},
+		{
+			name:  "several files with names",
+			files: []string{"a.txt", "sub/c.txt"},
+			want:  []string{"a.txt:mouse", "a.txt:mouse two", "sub/c.txt:mouse three"},
+		},
+		{
+			name:  "context is separated between files",
+			flags: map[string]interface{}{"-A": 1},
+			files: []string{"a.txt", "sub/c.txt"},
+			want:  []string{"a.txt:mouse", "a.txt-cat", "--", "a.txt:mouse two", "--", "sub/c.txt:mouse three"},
+		},
+		{
+			name:  "recursive",
+			flags: map[string]interface{}{"-r": true},
+			files: []string{"sub"},
+			want:  []string{"sub/c.txt:mouse three", "sub/d.log:mouse four"},
+		},
+		{
+			name:  "recursive on a single file",
+			flags: map[string]interface{}{"-r": true},
+			files: []string{"sub/d.log"},
+			want:  []string{"mouse four"},
+		},
+		{
+			name:  "include",
+			flags: map[string]interface{}{"-r": true, "--include": []string{"*.log"}},
+			want:  []string{"sub/d.log:mouse four"},
+		},
+		{
+			name:  "exclude",
+			flags: map[string]interface{}{"-r": true, "--exclude": []string{"*.log"}},
+			want:  []string{"a.txt:mouse", "a.txt:mouse two", "sub/c.txt:mouse three"},
+		},
+		{
+			name:  "explicit current directory",
+			flags: map[string]interface{}{"-r": true, "--include": []string{"*.log"}},
+			files: []string{"."},
+			want:  []string{"./sub/d.log:mouse four"},
+		},
+		{
+			name:  "files with matches",
+			flags: map[string]interface{}{"-r": true, "-l": true},
+			want:  []string{"a.txt", "sub/c.txt", "sub/d.log"},
+		},
+		{
+			name:  "files without matches",
+			flags: map[string]interface{}{"-r": true, "-L": true},
+			want:  []string{"b.log", "sub/e/f.txt"},
+		},
+		{
+			name:  "no file name",
+			flags: map[string]interface{}{"-h": true},
+			files: []string{"a.txt", "sub/d.log"},
+			want:  []string{"mouse", "mouse two", "mouse four"},
+		},
+		{
+			name:    "directory without -r",
+			files:   []string{"sub", "sub/d.log"},
+			want:    []string{"sub/d.log:mouse four"},
+			wantErr: "grep: sub: Is a directory\n",
+		},
+		{
+			name:    "missing file",
+			files:   []string{"missing.txt", "a.txt"},
+			want:    []string{"a.txt:mouse", "a.txt:mouse two"},
+			wantErr: "grep: missing.txt: No such file or directory\n",
+		},
+	}
+
+	// пути в выводе относительные, как при запуске grep из каталога с файлами
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+
+			g := newTestGrep("mouse", tt.flags)
+			g.files = tt.files
+
+			// без путей с -r ищется в текущем каталоге, как после SetArgs
+			if len(tt.files) == 0 {
+				g.files, g.implicitDir = []string{"."}, true
+			}
+			g.errOut = &errOut
+
+			if _, err := g.Grep(&out); err != nil {
+				t.Fatal(err)
+			}
+
+			if want := strings.Join(tt.want, "\n") + "\n"; out.String() != want {
+				t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+			}
+			if errOut.String() != tt.wantErr {
+				t.Errorf("errors = %q, want %q", errOut.String(), tt.wantErr)
+			}
+		})
+	}
+}
+
+// ожидаемые результаты получены с помощью GNU grep, сообщение о двоичном файле в формате GNU grep 2.x
+func TestBinaryFiles(t *testing.T) {
+	input := "mouse\x00\ncat\nmouse two\n"
+
+	tsts := []struct {
+		name    string
+		flags   map[string]interface{}
+		input   string
+		want    []string
+		matches int
+	}{
+		{
+			name:    "binary file matches",
+			flags:   map[string]interface{}{"-n": true, "-B": 1},
+			input:   input,
+			want:    []string{"Binary file bin.dat matches"},
+			matches: 1,
+		},
+		{
+			name:    "inverted",
+			flags:   map[string]interface{}{"-v": true},
+			input:   input,
+			want:    []string{"Binary file bin.dat matches"},
+			matches: 1,
+		},
+		{
+			name:    "no message without matches",
+			flags:   map[string]interface{}{"-x": true},
+			input:   input,
+			want:    nil,
+			matches: 0,
+		},
+		{
+			name:    "count",
+			flags:   map[string]interface{}{"-c": true},
+			input:   input,
+			want:    []string{"2"},
+			matches: 2,
+		},
+		{
+			name:    "as text",
+			flags:   map[string]interface{}{"--binary-files": binaryText},
+			input:   input,
+			want:    []string{"mouse\x00", "mouse two"},
+			matches: 2,
+		},
+		{
+			name:    "without match",
+			flags:   map[string]interface{}{"--binary-files": binaryWithoutMatch},
+			input:   input,
+			want:    nil,
+			matches: 0,
+		},
+		{
+			name:    "count without match",
+			flags:   map[string]interface{}{"--binary-files": binaryWithoutMatch, "-c": true},
+			input:   input,
+			want:    []string{"0"},
+			matches: 0,
+		},
+		{
+			name:    "files without match",
+			flags:   map[string]interface{}{"--binary-files": binaryWithoutMatch, "-L": true},
+			input:   input,
+			want:    []string{"bin.dat"},
+			matches: 0,
+		},
+		{
+			name:    "zero byte after the first block",
+			input:   strings.Repeat("x\n", binaryBlock) + "mouse\x00\n",
+			want:    []string{"mouse\x00"},
+			matches: 1,
+		},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGrep("mouse", tt.flags)
+			if err := g.compile(); err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			bw := bufio.NewWriter(&buf)
+
+			matches, err := g.search(strings.NewReader(tt.input), "bin.dat", newPrinter(bw, g.ca, g.m, false))
+			if err != nil {
+				t.Fatal(err)
+			}
+			bw.Flush()
+
+			want := ""
+			if len(tt.want) > 0 {
+				want = strings.Join(tt.want, "\n") + "\n"
+			}
+
+			if buf.String() != want || matches != tt.matches {
+				t.Errorf("got %q, %d matches, want %q, %d", buf.String(), matches, want, tt.matches)
+			}
+		})
+	}
+}
+
+// bzip2Data - "cat\nmouse\n", сжатый bzip2, в стандартной библиотеке есть только распаковка
+var bzip2Data = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x2f, 0x3c, 0x98, 0x47, 0x00, 0x00,
+	0x01, 0xc1, 0x80, 0x00, 0x10, 0x2a, 0x02, 0x8e, 0x00, 0x20, 0x00, 0x22, 0x06, 0x87, 0xa4, 0x20,
+	0xc9, 0x88, 0x46, 0x22, 0xb9, 0x8f, 0xc5, 0xdc, 0x91, 0x4e, 0x14, 0x24, 0x0b, 0xcf, 0x26, 0x11,
+	0xc0,
+}
+
+func TestDecompress(t *testing.T) {
+	dir := t.TempDir()
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte("mouse\ndog\nmouse two\n"))
+	zw.Close()
+
+	files := map[string][]byte{
+		"a.gz":   gz.Bytes(),
+		"b.bz2":  bzip2Data,
+		"c.txt":  []byte("mouse three\n"),
+		"bad.gz": []byte("mouse"),
+	}
+
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tsts := []struct {
+		name    string
+		flags   map[string]interface{}
+		files   []string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:  "compressed files",
+			flags: map[string]interface{}{"-z": true, "-n": true},
+			files: []string{"a.gz", "b.bz2", "c.txt"},
+			want:  []string{"a.gz:1:mouse", "a.gz:3:mouse two", "b.bz2:2:mouse", "c.txt:1:mouse three"},
+		},
+		{
+			name:    "broken archive",
+			flags:   map[string]interface{}{"-z": true},
+			files:   []string{"bad.gz", "c.txt"},
+			want:    []string{"c.txt:mouse three"},
+			wantErr: "grep: " + filepath.Join(dir, "bad.gz") + ": Unexpected EOF\n",
+		},
+	}
+
+	for _, tt := range tsts {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+
+			g := newTestGrep("mouse", tt.flags)
+			g.errOut = &errOut
+			for _, name := range tt.files {
+				g.files = append(g.files, filepath.Join(dir, name))
+			}
+
+			if _, err := g.Grep(&out); err != nil {
+				t.Fatal(err)
+			}
+
+			want := strings.Join(tt.want, "\n") + "\n"
+			if got := strings.ReplaceAll(out.String(), dir+string(filepath.Separator), ""); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+			if errOut.String() != tt.wantErr {
+				t.Errorf("errors = %q, want %q", errOut.String(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChunkReader(t *testing.T) {
+	long := strings.Repeat("x", chunkSize+10)
+	input := "a\n" + long + "\nb\n\nc"
+
+	chunks := newChunkReader(strings.NewReader(input))
+	var got []string
+
+	for {
+		lines, err := chunks.next()
+		for _, line := range lines {
+			got = append(got, string(line))
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"a", long, "b", "", "c"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d: got %.20q, want %.20q", i, got[i], want[i])
+		}
+	}
+}
+
+// testLines - n строк, каждая седьмая содержит "mouse"
+func testLines(n int) string {
+	var sb strings.Builder
+
+	for i := 0; i < n; i++ {
+		if i%7 == 0 {
+			fmt.Fprintf(&sb, "line %d with a mouse\n", i)
+			continue
+		}
+		fmt.Fprintf(&sb, "line %d with a cat\n", i)
+	}
+
+	return sb.String()
+}
+
+func TestParallelMatchesSequential(t *testing.T) {
+	input := testLines(200000)
+	flags := map[string]interface{}{"-B": 2, "-A": 1, "-n": true}
+
+	want, wantMatches, err := searchString(newTestGrep("mouse", flags), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, workers := range []int{2, 4} {
+		t.Run(fmt.Sprintf("parallel %d", workers), func(t *testing.T) {
+			g := newTestGrep("mouse", flags)
+			g.workers = workers
+
+			got, matches, err := searchString(g, input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if matches != wantMatches || got != want {
+				t.Errorf("parallel search differs from sequential: %d matches, want %d", matches, wantMatches)
+			}
+		})
+	}
+}
+
+func TestParallelFilesOrder(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+
+	for i := 0; i < 20; i++ {
+		name := filepath.Join(dir, fmt.Sprintf("f%02d.txt", i))
+		if err := os.WriteFile(name, []byte(testLines(100*i)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, name)
+
+		if i%5 == 0 {
+			files = append(files, filepath.Join(dir, fmt.Sprintf("missing%d", i)))
+		}
+	}
+
+	grep := func(workers int) (string, string, int) {
+		var out, errOut bytes.Buffer
+
+		g := newTestGrep("mouse", map[string]interface{}{"-A": 1})
+		g.files = files
+		g.errOut = &errOut
+		g.workers = workers
+
+		total, err := g.Grep(&out)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return out.String(), errOut.String(), total
+	}
+
+	wantOut, wantErr, wantTotal := grep(1)
+
+	for _, workers := range []int{2, 8} {
+		gotOut, gotErr, gotTotal := grep(workers)
+
+		if gotOut != wantOut || gotTotal != wantTotal {
+			t.Errorf("parallel %d: output differs from sequential", workers)
+		}
+		if gotErr != wantErr {
+			t.Errorf("parallel %d: errors = %q, want %q", workers, gotErr, wantErr)
+		}
+	}
+}
+
+// matchPerLine - прежний способ поиска: искомое значение компилируется заново для каждой строки
+func matchPerLine(target string, r io.Reader) int {
+	scanner := bufio.NewScanner(r)
+	matches := 0
+
+	for scanner.Scan() {
+		if ok, _ := regexp.Match(target, scanner.Bytes()); ok {
+			matches++
+		}
+	}
+
+	return matches
+}
+
+func BenchmarkSearch(b *testing.B) {
+	input := testLines(200000)
+	target := "mou[s]e"
+
+	b.Run("regexp.Match per line", func(b *testing.B) {
+		b.SetBytes(int64(len(input)))
+		for i := 0; i < b.N; i++ {
+			matchPerLine(target, strings.NewReader(input))
+		}
+	})
+
+	for _, workers := range []int{1, 4} {
+		b.Run(fmt.Sprintf("compiled parallel=%d", workers), func(b *testing.B) {
+			g := newTestGrep(target, map[string]interface{}{"-c": true})
+			g.workers = workers
+			if err := g.compile(); err != nil {
+				b.Fatal(err)
+			}
+
+			b.SetBytes(int64(len(input)))
+			for i := 0; i < b.N; i++ {
+				if _, err := g.search(strings.NewReader(input), "", newPrinter(bufio.NewWriter(io.Discard), g.ca, g.m, false)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGrepFiles(b *testing.B) {
+	dir := b.TempDir()
+	input := []byte(testLines(20000))
+
+	for i := 0; i < 32; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%02d.txt", i)), input, 0o644); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	for _, workers := range []int{1, 4} {
+		b.Run(fmt.Sprintf("parallel=%d", workers), func(b *testing.B) {
+			g := newTestGrep("mou[s]e", map[string]interface{}{"-r": true})
+			g.files = []string{dir}
+			g.workers = workers
+
+			for i := 0; i < b.N; i++ {
+				if _, err := g.Grep(io.Discard); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// ожидаемые результаты получены с помощью GNU grep
+func TestExitCode(t *testing.T) {
+	dir := t.TempDir()
+
+	for name, data := range map[string]string{"a.txt": "mouse\ncat\n", "b.txt": "dog\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tsts := []struct {
+		name    string
+		flags   map[string]interface{}
+		files   []string
+		want    string
+		wantErr string
+		code    int
+	}{
+		{
+			name:  "match",
+			files: []string{"a.txt"},
+			want:  "mouse\n",
+			code:  0,
+		},
+		{
+			name:  "no match",
+			files: []string{"b.txt"},
+			code:  1,
+		},
+		{
+			name:    "error is stronger than match",
+			files:   []string{"a.txt", "missing"},
+			want:    "a.txt:mouse\n",
+			wantErr: "grep: missing: No such file or directory\n",
+			code:    2,
+		},
+		{
+			name:    "quiet match is stronger than error",
+			flags:   map[string]interface{}{"-q": true},
+			files:   []string{"missing", "a.txt"},
+			wantErr: "grep: missing: No such file or directory\n",
+			code:    0,
+		},
+		{
+			name:  "quiet stops on the first match",
+			flags: map[string]interface{}{"-q": true},
+			files: []string{"a.txt", "missing"},
+			code:  0,
+		},
+		{
+			name:  "silent",
+			flags: map[string]interface{}{"-s": true},
+			files: []string{"missing", "sub", "b.txt"},
+			code:  2,
+		},
+		{
+			name:    "directory",
+			files:   []string{"sub", "a.txt"},
+			want:    "a.txt:mouse\n",
+			wantErr: "grep: sub: Is a directory\n",
+			code:    2,
+		},
+		{
+			name:  "count per file",
+			flags: map[string]interface{}{"-c": true},
+			files: []string{"a.txt", "b.txt"},
+			want:  "a.txt:1\nb.txt:0\n",
+			code:  0,
+		},
+		{
+			name:  "files without matches after a match",
+			flags: map[string]interface{}{"-L": true},
+			files: []string{"a.txt", "b.txt"},
+			want:  "b.txt\n",
+			code:  0,
+		},
+		{
+			name:  "files without matches",
+			flags: map[string]interface{}{"-L": true},
+			files: []string{"b.txt"},
+			want:  "b.txt\n",
+			code:  1,
+		},
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	for _, tt := range tsts {
+		for _, workers := range []int{1, 4} {
+			t.Run(fmt.Sprintf("%s parallel %d", tt.name, workers), func(t *testing.T) {
+				var out, errOut bytes.Buffer
+
+				g := newTestGrep("mouse", tt.flags)
+				g.files = tt.files
+				g.errOut = &errOut
+				g.workers = workers
+
+				matches, err := g.Grep(&out)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if out.String() != tt.want {
+					t.Errorf("got:\n%s\nwant:\n%s", out.String(), tt.want)
+				}
+				if errOut.String() != tt.wantErr {
+					t.Errorf("errors = %q, want %q", errOut.String(), tt.wantErr)
+				}
+				if code := g.exitCode(matches); code != tt.code {
+					t.Errorf("exit code = %d, want %d", code, tt.code)
+				}
+			})
+		}
+	}
+}